@@ -0,0 +1,233 @@
+// Package card renders "now playing" cards as self-contained SVG images which can be embedded
+// in READMEs and status pages.
+package card
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mgjules/spoty/palette"
+)
+
+// _fontFamily is the font stack of the cards, available on most systems.
+const _fontFamily = `-apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif`
+
+// _defaultBackground is the background of the cards without a color.
+var _defaultBackground = color.RGBA{R: 0x12, G: 0x12, B: 0x12, A: 0xff}
+
+// State represents what a card shows.
+type State string
+
+// States of a card.
+const (
+	// StatePlaying shows the item currently playing.
+	StatePlaying State = "playing"
+	// StatePaused shows the current item while playback is paused.
+	StatePaused State = "paused"
+	// StateLastPlayed shows the last played item when nothing is playing.
+	StateLastPlayed State = "last_played"
+	// StateIdle shows that nothing was played.
+	StateIdle State = "idle"
+)
+
+// Card represents the data shown on a card.
+type Card struct {
+	State      State
+	Title      string
+	Artists    []string
+	Collection string
+	// ProgressMs and DurationMs draw the progress bar of the playing and paused states.
+	ProgressMs int
+	DurationMs int
+	// PlayedAt is the moment the last played item was played.
+	PlayedAt time.Time
+	// Cover is an optional encoded cover thumbnail, embedded in the card.
+	Cover     []byte
+	CoverType string
+	// Color is the background of the card, usually the dominant color of the cover.
+	// The text color is chosen to contrast with it.
+	Color *color.RGBA
+	// Gradient are the colors of the background gradient of raster cards, from their top left corner
+	// to their bottom right one. Raster cards fall back to Color without at least two colors.
+	Gradient []color.RGBA
+}
+
+// WriteSVG writes the card as an SVG image with a layout.
+// The time is used to tell how long ago the last played item was played.
+func (c *Card) WriteSVG(w io.Writer, layout Layout, now time.Time) error {
+	l, ok := _layouts[layout]
+	if !ok {
+		return fmt.Errorf("unknown layout %q", layout)
+	}
+
+	return _svg.Execute(w, c.view(l, now))
+}
+
+// view represents the values of the template of a card.
+type view struct {
+	Layout     *layout
+	FontFamily string
+
+	Label      string
+	Title      string
+	Artists    string
+	Collection string
+
+	Background    string
+	Text          string
+	SecondaryText string
+
+	Cover string
+
+	Progress     bool
+	ProgressFrom float64
+	// ProgressAnimation is the remaining duration of a playing item, animating the progress bar.
+	ProgressAnimation int
+}
+
+func (c *Card) view(l *layout, now time.Time) *view {
+	bg := c.background()
+	contrast := palette.NewContrast(bg)
+
+	v := view{
+		Layout:        l,
+		FontFamily:    _fontFamily,
+		Label:         c.label(now),
+		Title:         truncate(c.title(), l.chars(l.TitleSize)),
+		Artists:       truncate(strings.Join(c.Artists, ", "), l.chars(l.TextSize)),
+		Background:    palette.Hex(bg),
+		Text:          contrast.Text.Hex,
+		SecondaryText: contrast.SecondaryText.Hex,
+	}
+
+	if l.CollectionY > 0 {
+		v.Collection = truncate(c.Collection, l.chars(l.TextSize))
+	}
+
+	if share, ok := c.progress(); ok {
+		v.Progress = true
+		v.ProgressFrom = float64(l.ProgressWidth) * share
+
+		if c.State == StatePlaying && share < 1 {
+			v.ProgressAnimation = c.DurationMs - c.ProgressMs
+		}
+	}
+
+	if len(c.Cover) > 0 && c.CoverType != "" {
+		v.Cover = "data:" + c.CoverType + ";base64," + base64.StdEncoding.EncodeToString(c.Cover)
+	}
+
+	return &v
+}
+
+// background returns the opaque background color of the card.
+func (c *Card) background() color.RGBA {
+	if c.Color == nil {
+		return _defaultBackground
+	}
+
+	bg := *c.Color
+	bg.A = 0xff
+
+	return bg
+}
+
+// label returns the label telling the state of the card.
+func (c *Card) label(now time.Time) string {
+	switch c.State {
+	case StatePlaying:
+		return "Now playing"
+	case StatePaused:
+		return "Paused"
+	case StateLastPlayed:
+		if c.PlayedAt.IsZero() {
+			return "Last played"
+		}
+
+		return "Last played · " + ago(now.Sub(c.PlayedAt))
+	default:
+		return "Not playing"
+	}
+}
+
+// title returns the title of the card, which is never empty.
+func (c *Card) title() string {
+	if c.Title == "" {
+		return "Nothing played yet"
+	}
+
+	return c.Title
+}
+
+// progress returns the share of the item played (0-1) and whether the card has a progress bar.
+func (c *Card) progress() (float64, bool) {
+	if (c.State != StatePlaying && c.State != StatePaused) || c.DurationMs <= 0 {
+		return 0, false
+	}
+
+	if c.ProgressMs >= c.DurationMs {
+		return 1, true
+	}
+
+	return float64(c.ProgressMs) / float64(c.DurationMs), true
+}
+
+// truncate shortens a text to at most n characters, ending it with an ellipsis if it is cut.
+func truncate(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) <= n || n < 1 {
+		return s
+	}
+
+	runes := []rune(s)
+
+	return strings.TrimSpace(string(runes[:n-1])) + "…"
+}
+
+// ago tells how long ago something happened, roughly.
+func ago(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%d min ago", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%d h ago", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%d d ago", int(d/(24*time.Hour)))
+	}
+}
+
+// escape escapes a text for XML.
+func escape(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s)) //nolint: errcheck
+
+	return sb.String()
+}
+
+var _svg = template.Must(template.New("card").Funcs(template.FuncMap{
+	"esc": escape,
+}).Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Layout.Width}}" height="{{.Layout.Height}}" viewBox="0 0 {{.Layout.Width}} {{.Layout.Height}}" role="img" aria-label="{{esc .Label}}: {{esc .Title}}">
+<title>{{esc .Label}}: {{esc .Title}}{{if .Artists}} by {{esc .Artists}}{{end}}</title>
+<defs><clipPath id="cover"><rect x="{{.Layout.CoverX}}" y="{{.Layout.CoverY}}" width="{{.Layout.CoverSize}}" height="{{.Layout.CoverSize}}" rx="{{.Layout.CoverRadius}}"/></clipPath></defs>
+<rect width="{{.Layout.Width}}" height="{{.Layout.Height}}" rx="{{.Layout.Radius}}" fill="{{.Background}}"/>
+{{if .Cover}}<image x="{{.Layout.CoverX}}" y="{{.Layout.CoverY}}" width="{{.Layout.CoverSize}}" height="{{.Layout.CoverSize}}" preserveAspectRatio="xMidYMid slice" clip-path="url(#cover)" href="{{.Cover}}"/>
+{{else}}<rect x="{{.Layout.CoverX}}" y="{{.Layout.CoverY}}" width="{{.Layout.CoverSize}}" height="{{.Layout.CoverSize}}" rx="{{.Layout.CoverRadius}}" fill="{{.Text}}" fill-opacity="0.12"/>
+{{end}}<g font-family="{{.FontFamily}}">
+<text x="{{.Layout.TextX}}" y="{{.Layout.LabelY}}" font-size="{{.Layout.LabelSize}}" font-weight="600" letter-spacing="0.08em" fill="{{.SecondaryText}}">{{esc .Label}}</text>
+<text x="{{.Layout.TextX}}" y="{{.Layout.TitleY}}" font-size="{{.Layout.TitleSize}}" font-weight="700" fill="{{.Text}}">{{esc .Title}}</text>
+{{if .Artists}}<text x="{{.Layout.TextX}}" y="{{.Layout.ArtistsY}}" font-size="{{.Layout.TextSize}}" fill="{{.Text}}">{{esc .Artists}}</text>
+{{end}}{{if .Collection}}<text x="{{.Layout.TextX}}" y="{{.Layout.CollectionY}}" font-size="{{.Layout.TextSize}}" fill="{{.SecondaryText}}">{{esc .Collection}}</text>
+{{end}}</g>
+{{if .Progress}}<rect x="{{.Layout.TextX}}" y="{{.Layout.ProgressY}}" width="{{.Layout.ProgressWidth}}" height="{{.Layout.ProgressHeight}}" rx="{{.Layout.ProgressRadius}}" fill="{{.Text}}" fill-opacity="0.25"/>
+<rect x="{{.Layout.TextX}}" y="{{.Layout.ProgressY}}" width="{{printf "%.1f" .ProgressFrom}}" height="{{.Layout.ProgressHeight}}" rx="{{.Layout.ProgressRadius}}" fill="{{.Text}}">{{if .ProgressAnimation}}<animate attributeName="width" from="{{printf "%.1f" .ProgressFrom}}" to="{{.Layout.ProgressWidth}}" dur="{{.ProgressAnimation}}ms" fill="freeze"/>{{end}}</rect>
+{{end}}</svg>
+`))
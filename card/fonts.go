@@ -0,0 +1,230 @@
+package card
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/mgjules/spoty/config"
+	"go.uber.org/fx"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// _ellipsis ends truncated texts.
+const _ellipsis = "…"
+
+// Module exported for initialising the Fonts of raster cards.
+var Module = fx.Options(
+	fx.Provide(NewFonts),
+)
+
+// Fonts represents the fonts used to draw raster cards: the embedded Go fonts followed by
+// the fallback fonts, which draw the characters the Go fonts lack (e.g. CJK).
+type Fonts struct {
+	regular []*opentype.Font
+	bold    []*opentype.Font
+}
+
+// NewFonts loads the configured fallback fonts.
+func NewFonts(cfg *config.Config) (*Fonts, error) {
+	return LoadFonts(cfg.CardFallbackFonts...)
+}
+
+// LoadFonts loads fallback fonts from TrueType or OpenType files, in order of preference.
+// Only the first font of a collection (.ttc) is used.
+func LoadFonts(paths ...string) (*Fonts, error) {
+	regular, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("parse regular font: %w", err)
+	}
+
+	bold, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("parse bold font: %w", err)
+	}
+
+	f := Fonts{
+		regular: []*opentype.Font{regular},
+		bold:    []*opentype.Font{bold},
+	}
+
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		fallback, err := loadFont(path)
+		if err != nil {
+			return nil, fmt.Errorf("load fallback font %q: %w", path, err)
+		}
+
+		f.regular = append(f.regular, fallback)
+		f.bold = append(f.bold, fallback)
+	}
+
+	return &f, nil
+}
+
+func loadFont(path string) (*opentype.Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if f, err := opentype.Parse(data); err == nil {
+		return f, nil
+	}
+
+	collection, err := opentype.ParseCollection(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if collection.NumFonts() == 0 {
+		return nil, fmt.Errorf("empty font collection")
+	}
+
+	return collection.Font(0)
+}
+
+// textFace draws texts with a list of fonts, picking for every character the first font which has it.
+// Like font.Face, it is not safe for concurrent use.
+type textFace struct {
+	size  float64
+	fonts []*opentype.Font
+	faces []font.Face
+	picks map[rune]int
+	buf   sfnt.Buffer
+}
+
+func newTextFace(fonts []*opentype.Font, size float64) (*textFace, error) {
+	f := textFace{
+		size:  size,
+		fonts: fonts,
+		faces: make([]font.Face, len(fonts)),
+		picks: make(map[rune]int),
+	}
+
+	for i, fnt := range fonts {
+		face, err := opentype.NewFace(fnt, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, fmt.Errorf("new face: %w", err)
+		}
+
+		f.faces[i] = face
+	}
+
+	return &f, nil
+}
+
+// pick returns the index of the face drawing a character.
+// Characters no font has are drawn as the missing glyph of the first one.
+func (f *textFace) pick(r rune) int {
+	if i, ok := f.picks[r]; ok {
+		return i
+	}
+
+	i := 0
+	for j, fnt := range f.fonts {
+		if index, err := fnt.GlyphIndex(&f.buf, r); err == nil && index != 0 {
+			i = j
+
+			break
+		}
+	}
+
+	f.picks[r] = i
+
+	return i
+}
+
+// ascent returns the ascent of the first face, in pixels.
+func (f *textFace) ascent() int {
+	return f.faces[0].Metrics().Ascent.Ceil()
+}
+
+// descent returns the descent of the first face, in pixels.
+func (f *textFace) descent() int {
+	return f.faces[0].Metrics().Descent.Ceil()
+}
+
+// measure returns the width of a text, in pixels.
+func (f *textFace) measure(s string) int {
+	return f.walk(s, nil).Ceil()
+}
+
+// draw draws a text with its baseline starting at (x, y) and returns its width.
+func (f *textFace) draw(dst draw.Image, src image.Image, x, y int, s string) int {
+	origin := fixed.P(x, y)
+
+	return f.walk(s, func(face font.Face, r rune, dx fixed.Int26_6) {
+		dot := fixed.Point26_6{X: origin.X + dx, Y: origin.Y}
+		if dr, mask, maskp, _, ok := face.Glyph(dot, r); ok {
+			draw.DrawMask(dst, dr, src, image.Point{}, mask, maskp, draw.Over)
+		}
+	}).Ceil()
+}
+
+// walk lays out a text, calling fn with the face and offset of every character, and returns its width.
+// Characters are kerned with the previous one when both are drawn by the same face.
+func (f *textFace) walk(s string, fn func(face font.Face, r rune, x fixed.Int26_6)) fixed.Int26_6 {
+	var (
+		x    fixed.Int26_6
+		prev = -1
+		last rune
+	)
+
+	for _, r := range s {
+		i := f.pick(r)
+		face := f.faces[i]
+
+		if i == prev {
+			x += face.Kern(last, r)
+		}
+
+		if fn != nil {
+			fn(face, r, x)
+		}
+
+		advance, _ := face.GlyphAdvance(r)
+		x += advance
+		prev, last = i, r
+	}
+
+	return x
+}
+
+// truncate shortens a text to fit in a width, in pixels, ending it with an ellipsis if it is cut.
+// Control characters are replaced with spaces.
+func (f *textFace) truncate(s string, width int) string {
+	s = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+
+		return r
+	}, s))
+
+	if f.measure(s) <= width {
+		return s
+	}
+
+	runes := []rune(s)
+	for n := len(runes) - 1; n > 0; n-- {
+		cut := strings.TrimSpace(string(runes[:n])) + _ellipsis
+		if f.measure(cut) <= width {
+			return cut
+		}
+	}
+
+	return ""
+}
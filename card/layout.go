@@ -0,0 +1,79 @@
+package card
+
+// Layout represents the template of a card.
+type Layout string
+
+// Layouts of a card.
+const (
+	// LayoutCompact is a small badge with a thumbnail, the title and the artists.
+	LayoutCompact Layout = "compact"
+	// LayoutClassic is a banner with the cover on the left of the details.
+	LayoutClassic Layout = "classic"
+	// LayoutCover is a tile with a large cover above the details.
+	LayoutCover Layout = "cover"
+)
+
+// Valid tells whether the layout is known.
+func (l Layout) Valid() bool {
+	_, ok := _layouts[l]
+
+	return ok
+}
+
+// CoverSize returns the size of the cover drawn by the layout, in pixels.
+func (l Layout) CoverSize() int {
+	if layout, ok := _layouts[l]; ok {
+		return layout.CoverSize
+	}
+
+	return 0
+}
+
+// layout represents the geometry of a card, in pixels.
+type layout struct {
+	Width, Height int
+	Radius        int
+
+	CoverX, CoverY, CoverSize, CoverRadius int
+
+	// TextX is the left of the texts and of the progress bar, which spans TextWidth.
+	TextX, TextWidth int
+	// A zero CollectionY hides the album or show.
+	LabelY, TitleY, ArtistsY, CollectionY int
+	LabelSize, TitleSize, TextSize        int
+
+	ProgressY, ProgressWidth, ProgressHeight, ProgressRadius int
+}
+
+// chars returns the number of characters of a font size fitting in the width of the texts.
+// Characters are assumed to be 0.6em wide on average.
+func (l *layout) chars(fontSize int) int {
+	return int(float64(l.TextWidth) / (0.6 * float64(fontSize)))
+}
+
+var _layouts = map[Layout]*layout{
+	LayoutCompact: {
+		Width: 360, Height: 72, Radius: 8,
+		CoverX: 8, CoverY: 8, CoverSize: 56, CoverRadius: 4,
+		TextX: 76, TextWidth: 272,
+		LabelY: 22, TitleY: 41, ArtistsY: 58,
+		LabelSize: 9, TitleSize: 14, TextSize: 12,
+		ProgressY: 66, ProgressWidth: 272, ProgressHeight: 2, ProgressRadius: 1,
+	},
+	LayoutClassic: {
+		Width: 480, Height: 144, Radius: 10,
+		CoverX: 12, CoverY: 12, CoverSize: 120, CoverRadius: 6,
+		TextX: 148, TextWidth: 316,
+		LabelY: 34, TitleY: 62, ArtistsY: 85, CollectionY: 105,
+		LabelSize: 11, TitleSize: 20, TextSize: 14,
+		ProgressY: 122, ProgressWidth: 316, ProgressHeight: 4, ProgressRadius: 2,
+	},
+	LayoutCover: {
+		Width: 300, Height: 400, Radius: 12,
+		CoverX: 12, CoverY: 12, CoverSize: 276, CoverRadius: 8,
+		TextX: 12, TextWidth: 276,
+		LabelY: 310, TitleY: 336, ArtistsY: 358, CollectionY: 378,
+		LabelSize: 11, TitleSize: 18, TextSize: 13,
+		ProgressY: 388, ProgressWidth: 276, ProgressHeight: 4, ProgressRadius: 2,
+	},
+}
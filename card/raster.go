@@ -0,0 +1,356 @@
+package card
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg" // decode embedded jpeg covers.
+	"image/png"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/mgjules/spoty/cover"
+	"github.com/mgjules/spoty/palette"
+)
+
+const (
+	// _lineHeight is the height of a line of text relative to its font size.
+	_lineHeight = 1.4
+	// _secondaryAlpha is the opacity of the secondary texts.
+	_secondaryAlpha = 0xb3
+	// _placeholderAlpha is the opacity of the placeholder drawn without a cover.
+	_placeholderAlpha = 0x1f
+	// _trackAlpha is the opacity of the track of the progress bar.
+	_trackAlpha = 0x40
+)
+
+// Preset represents the size of a raster card.
+type Preset string
+
+// Presets of a raster card.
+const (
+	// PresetSmall is a 600x200 banner.
+	PresetSmall Preset = "small"
+	// PresetOpenGraph is a 1200x630 banner, the size of link previews.
+	PresetOpenGraph Preset = "og"
+	// PresetSquare is a 1080x1080 tile.
+	PresetSquare Preset = "square"
+	// PresetStory is a 1080x1920 portrait, the size of stories.
+	PresetStory Preset = "story"
+)
+
+var _presets = map[Preset]image.Point{
+	PresetSmall:     {X: 600, Y: 200},
+	PresetOpenGraph: {X: 1200, Y: 630},
+	PresetSquare:    {X: 1080, Y: 1080},
+	PresetStory:     {X: 1080, Y: 1920},
+}
+
+// Valid tells whether the preset is known.
+func (p Preset) Valid() bool {
+	_, ok := _presets[p]
+
+	return ok
+}
+
+// CoverSize returns the size of the cover drawn by the preset, in pixels.
+func (p Preset) CoverSize() int {
+	size, ok := _presets[p]
+	if !ok {
+		return 0
+	}
+
+	return newRasterLayout(size.X, size.Y).cover.Dx()
+}
+
+// rasterLayout represents the geometry of a raster card, in pixels.
+// Landscape cards have the cover on the left of the texts, other cards have it above them.
+type rasterLayout struct {
+	bounds image.Rectangle
+	cover  image.Rectangle
+	// text is the area of the texts and of the progress bar.
+	text image.Rectangle
+	// centered tells whether the texts are centered horizontally in their area.
+	// Otherwise, they are left aligned and centered vertically.
+	centered bool
+
+	labelSize, titleSize, textSize float64
+}
+
+func newRasterLayout(width, height int) *rasterLayout {
+	l := rasterLayout{bounds: image.Rect(0, 0, width, height)}
+
+	if 2*width >= 3*height {
+		pad := height / 10
+		side := height - 2*pad
+
+		l.cover = image.Rect(pad, pad, pad+side, pad+side)
+		l.text = image.Rect(2*pad+side, pad, width-pad, height-pad)
+		l.labelSize, l.titleSize, l.textSize = 0.055*float64(height), 0.11*float64(height), 0.075*float64(height)
+
+		return &l
+	}
+
+	pad := width / 16
+	l.labelSize, l.titleSize, l.textSize = 0.032*float64(width), 0.06*float64(width), 0.042*float64(width)
+	textHeight := int(0.3 * float64(width))
+
+	side := width - 2*pad
+	if fit := height - 3*pad - textHeight; fit < side {
+		side = fit
+	}
+
+	top := (height - side - pad - textHeight) / 2
+	x := (width - side) / 2
+
+	l.cover = image.Rect(x, top, x+side, top+side)
+	l.text = image.Rect(pad, top+side+pad, width-pad, top+side+pad+textHeight)
+	l.centered = true
+
+	return &l
+}
+
+// WritePNG writes the card as a PNG image with a size preset, drawing its texts with fonts.
+// The time is used to tell how long ago the last played item was played.
+func (c *Card) WritePNG(w io.Writer, fonts *Fonts, preset Preset, now time.Time) error {
+	size, ok := _presets[preset]
+	if !ok {
+		return fmt.Errorf("unknown preset %q", preset)
+	}
+
+	img, err := c.raster(fonts, newRasterLayout(size.X, size.Y), now)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(w, img); err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+
+	return nil
+}
+
+// PNGKey returns a key identifying the PNG image of the card with a size preset at a given time:
+// cards with the same key are drawn the same. The progress is only accounted for up to the pixel.
+func (c *Card) PNGKey(preset Preset, now time.Time) string {
+	bar := -1
+	if share, ok := c.progress(); ok {
+		size := _presets[preset]
+		bar = int(math.Round(share * float64(newRasterLayout(size.X, size.Y).text.Dx())))
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%q\x00%s\x00%d\x00%v\x00%v\x00", //nolint: errcheck
+		preset, c.label(now), c.title(), c.Artists, c.Collection, bar, c.background(), c.Gradient)
+	h.Write(c.Cover) //nolint: errcheck
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// raster draws the card.
+func (c *Card) raster(fonts *Fonts, l *rasterLayout, now time.Time) (*image.RGBA, error) {
+	stops := c.Gradient
+	if len(stops) < 2 {
+		stops = []color.RGBA{c.background()}
+	}
+
+	dst := image.NewRGBA(l.bounds)
+	gradient(dst, stops)
+
+	fg := textColor(stops)
+	text := image.NewUniform(fg)
+	secondary := image.NewUniform(color.NRGBA{R: fg.R, G: fg.G, B: fg.B, A: _secondaryAlpha})
+
+	if err := c.drawCover(dst, l.cover, fg); err != nil {
+		return nil, err
+	}
+
+	type line struct {
+		text string
+		face *textFace
+		src  image.Image
+	}
+
+	label, err := newTextFace(fonts.bold, l.labelSize)
+	if err != nil {
+		return nil, err
+	}
+
+	title, err := newTextFace(fonts.bold, l.titleSize)
+	if err != nil {
+		return nil, err
+	}
+
+	regular, err := newTextFace(fonts.regular, l.textSize)
+	if err != nil {
+		return nil, err
+	}
+
+	width := l.text.Dx()
+	lines := []line{
+		{label.truncate(c.label(now), width), label, secondary},
+		{title.truncate(c.title(), width), title, text},
+		{regular.truncate(strings.Join(c.Artists, ", "), width), regular, text},
+		{regular.truncate(c.Collection, width), regular, secondary},
+	}
+
+	share, progress := c.progress()
+	barHeight := int(math.Max(2, math.Round(l.titleSize/10)))
+
+	height := 0
+	for _, ln := range lines {
+		height += int(ln.face.size * _lineHeight)
+	}
+
+	if progress {
+		height += barHeight + int(l.textSize/2)
+	}
+
+	y := l.text.Min.Y
+	if !l.centered {
+		y += (l.text.Dy() - height) / 2
+	}
+
+	for _, ln := range lines {
+		lineHeight := int(ln.face.size * _lineHeight)
+		baseline := y + (lineHeight-ln.face.ascent()-ln.face.descent())/2 + ln.face.ascent()
+
+		x := l.text.Min.X
+		if l.centered {
+			x += (width - ln.face.measure(ln.text)) / 2
+		}
+
+		ln.face.draw(dst, ln.src, x, baseline, ln.text)
+		y += lineHeight
+	}
+
+	if progress {
+		y += int(l.textSize / 2)
+		bar := image.Rect(l.text.Min.X, y, l.text.Max.X, y+barHeight)
+
+		draw.Draw(dst, bar, image.NewUniform(color.NRGBA{R: fg.R, G: fg.G, B: fg.B, A: _trackAlpha}), image.Point{}, draw.Over)
+
+		bar.Max.X = bar.Min.X + int(math.Round(share*float64(bar.Dx())))
+		draw.Draw(dst, bar, text, image.Point{}, draw.Over)
+	}
+
+	return dst, nil
+}
+
+// drawCover draws the cover of the card with rounded corners, or a placeholder without one.
+func (c *Card) drawCover(dst draw.Image, r image.Rectangle, fg color.RGBA) error {
+	mask := &roundedRect{size: r.Size(), radius: float64(r.Dx()) / 24}
+
+	if len(c.Cover) == 0 {
+		placeholder := image.NewUniform(color.NRGBA{R: fg.R, G: fg.G, B: fg.B, A: _placeholderAlpha})
+		draw.DrawMask(dst, r, placeholder, image.Point{}, mask, image.Point{}, draw.Over)
+
+		return nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(c.Cover))
+	if err != nil {
+		return fmt.Errorf("decode cover: %w", err)
+	}
+
+	resized := cover.Resize(img, r.Dx(), r.Dy(), cover.FitCover)
+	draw.DrawMask(dst, r, resized, resized.Bounds().Min, mask, image.Point{}, draw.Over)
+
+	return nil
+}
+
+// gradient fills an image with a diagonal gradient going evenly through colors,
+// from its top left corner to its bottom right one.
+func gradient(dst *image.RGBA, stops []color.RGBA) {
+	b := dst.Bounds()
+	w, h := float64(b.Dx()), float64(b.Dy())
+	segments := float64(len(stops) - 1)
+
+	for y := 0; y < b.Dy(); y++ {
+		row := dst.Pix[y*dst.Stride:]
+
+		for x := 0; x < b.Dx(); x++ {
+			c := stops[0]
+
+			if segments > 0 {
+				t := (float64(x)/w + float64(y)/h) / 2 * segments
+				i := int(t)
+				if i >= len(stops)-1 {
+					i = len(stops) - 2
+				}
+
+				c = mix(stops[i], stops[i+1], t-float64(i))
+			}
+
+			row[4*x], row[4*x+1], row[4*x+2], row[4*x+3] = c.R, c.G, c.B, 0xff
+		}
+	}
+}
+
+// mix blends two colors, t being the share of the second one (0-1).
+func mix(a, b color.RGBA, t float64) color.RGBA {
+	lerp := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t))
+	}
+
+	return color.RGBA{R: lerp(a.R, b.R), G: lerp(a.G, b.G), B: lerp(a.B, b.B), A: 0xff}
+}
+
+// textColor returns black or white, whichever contrasts the most with all the colors of a background.
+func textColor(bg []color.RGBA) color.RGBA {
+	white := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	black := color.RGBA{A: 0xff}
+
+	whiteRatio, blackRatio := math.Inf(1), math.Inf(1)
+	for _, c := range bg {
+		whiteRatio = math.Min(whiteRatio, palette.ContrastRatio(white, c))
+		blackRatio = math.Min(blackRatio, palette.ContrastRatio(black, c))
+	}
+
+	if blackRatio > whiteRatio {
+		return black
+	}
+
+	return white
+}
+
+// roundedRect is the antialiased mask of a rectangle with rounded corners, at the origin.
+type roundedRect struct {
+	size   image.Point
+	radius float64
+}
+
+func (r *roundedRect) ColorModel() color.Model {
+	return color.AlphaModel
+}
+
+func (r *roundedRect) Bounds() image.Rectangle {
+	return image.Rectangle{Max: r.size}
+}
+
+func (r *roundedRect) At(x, y int) color.Color {
+	px, py := float64(x)+0.5, float64(y)+0.5
+	w, h := float64(r.size.X), float64(r.size.Y)
+
+	// Distance to the center of the closest corner, along each axis, when within a corner.
+	dx := math.Max(r.radius-px, px-(w-r.radius))
+	dy := math.Max(r.radius-py, py-(h-r.radius))
+
+	if dx <= 0 || dy <= 0 {
+		if px < 0 || py < 0 || px > w || py > h {
+			return color.Alpha{}
+		}
+
+		return color.Alpha{A: 0xff}
+	}
+
+	coverage := math.Max(0, math.Min(1, r.radius-math.Hypot(dx, dy)+0.5))
+
+	return color.Alpha{A: uint8(math.Round(coverage * 0xff))}
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/mgjules/spoty/config"
+	"github.com/mgjules/spoty/health"
+	"github.com/mgjules/spoty/history"
+	"github.com/mgjules/spoty/logger"
+	"github.com/mgjules/spoty/store"
+	"github.com/mgjules/spoty/tracer"
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+)
+
+var (
+	exportFormat string
+	exportFrom   string
+	exportTo     string
+	exportOutput string
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the listening history",
+	Long: `Export the locally recorded listening history, oldest first, as CSV, NDJSON, JSON or a ListenBrainz import.
+The database is locked by a running server; use the /api/history/export endpoint instead in that case.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := history.ParseFormat(exportFormat)
+		if err != nil {
+			return err
+		}
+
+		from, err := parseTimeFlag(exportFrom)
+		if err != nil {
+			return fmt.Errorf("invalid from: %w", err)
+		}
+
+		to, err := parseTimeFlag(exportTo)
+		if err != nil {
+			return fmt.Errorf("invalid to: %w", err)
+		}
+
+		query := history.ExportQuery{
+			From:   from,
+			To:     to,
+			Format: format,
+		}
+
+		return runWithHistory(func(ctx context.Context, h *history.History) error {
+			if exportOutput == "" || exportOutput == "-" {
+				return export(ctx, h, os.Stdout, query)
+			}
+
+			f, err := os.Create(exportOutput)
+			if err != nil {
+				return fmt.Errorf("create output: %w", err)
+			}
+
+			if err := export(ctx, h, f, query); err != nil {
+				f.Close() //nolint: errcheck
+
+				return err
+			}
+
+			// A failed close may leave the export truncated.
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("close output: %w", err)
+			}
+
+			return nil
+		})
+	},
+}
+
+// export writes the plays matching the query to w and reports their number.
+func export(ctx context.Context, h *history.History, w io.Writer, q history.ExportQuery) error {
+	n, err := h.Export(ctx, w, q)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "exported %d plays\n", n)
+
+	return nil
+}
+
+// runWithHistory starts the components required to access the history, runs fn and stops them.
+// Only the local part of the configuration is loaded.
+func runWithHistory(fn func(ctx context.Context, h *history.History) error) error {
+	var h *history.History
+
+	app := fx.New(
+		fx.NopLogger,
+		fx.Provide(config.NewLocal),
+		logger.Module,
+		tracer.Module,
+		health.Module,
+		store.Module,
+		history.Module,
+		fx.Populate(&h),
+	)
+
+	ctx := context.Background()
+	if err := app.Start(ctx); err != nil {
+		return err
+	}
+
+	runErr := fn(ctx, h)
+
+	if err := app.Stop(ctx); err != nil && runErr == nil {
+		return err
+	}
+
+	return runErr
+}
+
+// parseTimeFlag parses an optional RFC3339 time.
+func parseTimeFlag(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, s)
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", string(history.FormatNDJSON), "export format (csv, ndjson, json, listenbrainz)")
+	exportCmd.Flags().StringVar(&exportFrom, "from", "", "RFC3339 time; exports plays started at or after it")
+	exportCmd.Flags().StringVar(&exportTo, "to", "", "RFC3339 time; exports plays started before it")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "-", "output file (- for stdout)")
+
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mgjules/spoty/history"
+	"github.com/mgjules/spoty/spoty"
+	"github.com/spf13/cobra"
+)
+
+// _importBatchSize is the number of plays imported per transaction.
+const _importBatchSize = 1000
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import <path>...",
+	Short: "Import the listening history from a spotify data export",
+	Long: `Import the listening history from the StreamingHistory*.json, endsong_*.json and Streaming_History_Audio_*.json
+files of a spotify data export ("Download your data"). Paths can be files, directories or the downloaded zip archives.
+Plays already in the history are skipped.
+The database is locked by a running server; stop it before importing.`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWithHistory(func(ctx context.Context, h *history.History) error {
+			var (
+				total   history.ImportResult
+				skipped int
+			)
+
+			for _, path := range args {
+				if err := eachDataExportFile(path, func(name string, r io.Reader) error {
+					result, err := spoty.ReadDataExport(name, r)
+					if err != nil {
+						return err
+					}
+
+					imported, err := importPlays(ctx, h, name, result.Plays)
+					if err != nil {
+						return err
+					}
+
+					fmt.Fprintf(
+						os.Stderr,
+						"%s: imported %d, duplicates %d, skipped %d\n",
+						name, imported.Imported, imported.Duplicates, result.Skipped,
+					)
+
+					total.Add(imported)
+					skipped += result.Skipped
+
+					return nil
+				}); err != nil {
+					return err
+				}
+			}
+
+			fmt.Fprintf(
+				os.Stderr,
+				"done: imported %d, duplicates %d, skipped %d\n",
+				total.Imported, total.Duplicates, skipped,
+			)
+
+			return nil
+		})
+	},
+}
+
+// importPlays imports plays in batches and reports the progress.
+func importPlays(ctx context.Context, h *history.History, name string, plays []*history.Play) (history.ImportResult, error) {
+	var result history.ImportResult
+
+	for start := 0; start < len(plays); start += _importBatchSize {
+		end := start + _importBatchSize
+		if end > len(plays) {
+			end = len(plays)
+		}
+
+		batch, err := h.Import(ctx, plays[start:end])
+		if err != nil {
+			return result, fmt.Errorf("import %s: %w", name, err)
+		}
+
+		result.Add(batch)
+
+		fmt.Fprintf(os.Stderr, "\r%s: %d/%d", name, end, len(plays))
+	}
+
+	if len(plays) > 0 {
+		fmt.Fprint(os.Stderr, "\r")
+	}
+
+	return result, nil
+}
+
+// eachDataExportFile calls fn for every streaming history file found at path.
+// The path can be a file, a directory or a zip archive.
+func eachDataExportFile(path string, fn func(name string, r io.Reader) error) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.IsDir():
+		return filepath.WalkDir(path, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() || !isArchive(name) && !spoty.IsDataExportFile(name) {
+				return nil
+			}
+
+			return eachDataExportFile(name, fn)
+		})
+	case isArchive(path):
+		zr, err := zip.OpenReader(path)
+		if err != nil {
+			return fmt.Errorf("open archive: %w", err)
+		}
+		defer zr.Close() //nolint: errcheck
+
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() || !spoty.IsDataExportFile(f.Name) {
+				continue
+			}
+
+			if err := readZipFile(f, fn); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case spoty.IsDataExportFile(path):
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close() //nolint: errcheck
+
+		return fn(path, f)
+	default:
+		return fmt.Errorf("%w: %s", spoty.ErrUnknownDataExport, path)
+	}
+}
+
+func readZipFile(f *zip.File, fn func(name string, r io.Reader) error) error {
+	r, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("open %s: %w", f.Name, err)
+	}
+	defer r.Close() //nolint: errcheck
+
+	return fn(f.Name, r)
+}
+
+func isArchive(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".zip")
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+}
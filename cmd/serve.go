@@ -5,10 +5,14 @@ import (
 
 	"github.com/mgjules/spoty/build"
 	"github.com/mgjules/spoty/cache"
+	"github.com/mgjules/spoty/card"
 	"github.com/mgjules/spoty/config"
+	"github.com/mgjules/spoty/diskcache"
 	"github.com/mgjules/spoty/health"
+	"github.com/mgjules/spoty/history"
 	"github.com/mgjules/spoty/logger"
 	"github.com/mgjules/spoty/spoty"
+	"github.com/mgjules/spoty/store"
 	"github.com/mgjules/spoty/tracer"
 	"github.com/mgjules/spoty/transport/http"
 	"github.com/mgjules/spoty/transport/messenger"
@@ -27,7 +31,11 @@ var serveCmd = &cobra.Command{
 			logger.Module,
 			tracer.Module,
 			cache.Module,
+			diskcache.Module,
+			card.Module,
 			health.Module,
+			store.Module,
+			history.Module,
 			messenger.Module,
 			http.Module,
 			spoty.Module,
@@ -0,0 +1,340 @@
+package colors
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// HSL represents a color by its hue (0-360), saturation (0-1) and lightness (0-1).
+type HSL struct {
+	H float64 `json:"h"`
+	S float64 `json:"s"`
+	L float64 `json:"l"`
+}
+
+// HSV represents a color by its hue (0-360), saturation (0-1) and value (0-1).
+type HSV struct {
+	H float64 `json:"h"`
+	S float64 `json:"s"`
+	V float64 `json:"v"`
+}
+
+// OKLab represents a color in the OKLab perceptual color space.
+// L is the perceived lightness (0-1); A and B are the green-red and blue-yellow axes.
+// See https://bottosson.github.io/posts/oklab/.
+type OKLab struct {
+	L float64 `json:"l"`
+	A float64 `json:"a"`
+	B float64 `json:"b"`
+}
+
+// OKLCH represents a color in the cylindrical form of OKLab:
+// lightness (0-1), chroma (0-~0.37) and hue (0-360).
+type OKLCH struct {
+	L float64 `json:"l"`
+	C float64 `json:"c"`
+	H float64 `json:"h"`
+}
+
+// Spaces represents a color in the supported color spaces.
+// Values are rounded for presentation.
+type Spaces struct {
+	HSL   HSL   `json:"hsl"`
+	HSV   HSV   `json:"hsv"`
+	OKLab OKLab `json:"oklab"`
+	OKLCH OKLCH `json:"oklch"`
+}
+
+// Convert returns a color in every supported color space.
+func Convert(c color.RGBA) *Spaces {
+	hsl := ToHSL(c)
+	hsv := ToHSV(c)
+	lab := ToOKLab(c)
+	lch := lab.OKLCH()
+
+	return &Spaces{
+		HSL:   HSL{H: round(hsl.H, 2), S: round(hsl.S, 4), L: round(hsl.L, 4)},
+		HSV:   HSV{H: round(hsv.H, 2), S: round(hsv.S, 4), V: round(hsv.V, 4)},
+		OKLab: OKLab{L: round(lab.L, 4), A: round(lab.A, 4), B: round(lab.B, 4)},
+		OKLCH: OKLCH{L: round(lch.L, 4), C: round(lch.C, 4), H: round(lch.H, 2)},
+	}
+}
+
+// Hex returns the hex representation of a color (e.g. #1DB954).
+func Hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
+
+// ToHSL converts a color to HSL.
+func ToHSL(c color.RGBA) HSL {
+	r, g, b := unit(c.R), unit(c.G), unit(c.B)
+	maxC, minC := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	l := (maxC + minC) / 2
+
+	if maxC == minC {
+		return HSL{L: l}
+	}
+
+	d := maxC - minC
+
+	return HSL{
+		H: hue(r, g, b, maxC, d),
+		S: d / (1 - math.Abs(2*l-1)),
+		L: l,
+	}
+}
+
+// RGBA converts the color to RGBA.
+func (c HSL) RGBA() color.RGBA {
+	chroma := (1 - math.Abs(2*c.L-1)) * c.S
+
+	return fromChroma(c.H, chroma, c.L-chroma/2)
+}
+
+// CSS returns the CSS representation of the color.
+func (c HSL) CSS() string {
+	return fmt.Sprintf("hsl(%s %s%% %s%%)", num(c.H, 2), num(c.S*100, 2), num(c.L*100, 2))
+}
+
+// ToHSV converts a color to HSV.
+func ToHSV(c color.RGBA) HSV {
+	r, g, b := unit(c.R), unit(c.G), unit(c.B)
+	maxC, minC := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+
+	if maxC == minC {
+		return HSV{V: maxC}
+	}
+
+	d := maxC - minC
+
+	return HSV{
+		H: hue(r, g, b, maxC, d),
+		S: d / maxC,
+		V: maxC,
+	}
+}
+
+// RGBA converts the color to RGBA.
+func (c HSV) RGBA() color.RGBA {
+	chroma := c.V * c.S
+
+	return fromChroma(c.H, chroma, c.V-chroma)
+}
+
+// ToOKLab converts a color to OKLab.
+func ToOKLab(c color.RGBA) OKLab {
+	r, g, b := Linear(c.R), Linear(c.G), Linear(c.B)
+
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+
+	return OKLab{
+		L: 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		A: 1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		B: 0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+// RGBA converts the color to RGBA. Out of gamut colors are clipped.
+func (c OKLab) RGBA() color.RGBA {
+	r, g, b := c.LinearRGB()
+
+	return color.RGBA{R: Encode(r), G: Encode(g), B: Encode(b), A: math.MaxUint8}
+}
+
+// InGamut tells whether the color can be represented in sRGB.
+func (c OKLab) InGamut() bool {
+	const eps = 1e-4
+
+	r, g, b := c.LinearRGB()
+
+	return r >= -eps && r <= 1+eps && g >= -eps && g <= 1+eps && b >= -eps && b <= 1+eps
+}
+
+// OKLCH converts the color to OKLCH.
+// The hue of achromatic colors is 0.
+func (c OKLab) OKLCH() OKLCH {
+	const achromatic = 1e-4
+
+	chroma := math.Hypot(c.A, c.B)
+	if chroma < achromatic {
+		return OKLCH{L: c.L, C: chroma}
+	}
+
+	h := math.Atan2(c.B, c.A) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+
+	return OKLCH{
+		L: c.L,
+		C: chroma,
+		H: h,
+	}
+}
+
+// CSS returns the CSS representation of the color.
+func (c OKLab) CSS() string {
+	return fmt.Sprintf("oklab(%s%% %s %s)", num(c.L*100, 2), num(c.A, 4), num(c.B, 4))
+}
+
+// LinearRGB converts the color to linear sRGB. Channels are not clipped.
+func (c OKLab) LinearRGB() (float64, float64, float64) {
+	l := c.L + 0.3963377774*c.A + 0.2158037573*c.B
+	m := c.L - 0.1055613458*c.A - 0.0638541728*c.B
+	s := c.L - 0.0894841775*c.A - 1.2914855480*c.B
+
+	l, m, s = l*l*l, m*m*m, s*s*s
+
+	return 4.0767416621*l - 3.3077115913*m + 0.2309699292*s,
+		-1.2684380046*l + 2.6097574011*m - 0.3413193965*s,
+		-0.0041960863*l - 0.7034186147*m + 1.7076147010*s
+}
+
+// ToOKLCH converts a color to OKLCH.
+func ToOKLCH(c color.RGBA) OKLCH {
+	return ToOKLab(c).OKLCH()
+}
+
+// OKLab converts the color to OKLab.
+func (c OKLCH) OKLab() OKLab {
+	h := c.H * math.Pi / 180
+
+	return OKLab{
+		L: c.L,
+		A: c.C * math.Cos(h),
+		B: c.C * math.Sin(h),
+	}
+}
+
+// RGBA converts the color to RGBA.
+// Out of gamut colors are mapped into sRGB by reducing their chroma.
+func (c OKLCH) RGBA() color.RGBA {
+	return c.ClampChroma().OKLab().RGBA()
+}
+
+// ClampChroma returns the color with the highest chroma, up to its own, that fits in sRGB.
+func (c OKLCH) ClampChroma() OKLCH {
+	if c.OKLab().InGamut() {
+		return c
+	}
+
+	lo, hi := 0.0, c.C
+	for i := 0; i < 24; i++ {
+		mid := (lo + hi) / 2
+		if (OKLCH{L: c.L, C: mid, H: c.H}).OKLab().InGamut() {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return OKLCH{L: c.L, C: lo, H: c.H}
+}
+
+// CSS returns the CSS representation of the color.
+func (c OKLCH) CSS() string {
+	return fmt.Sprintf("oklch(%s%% %s %s)", num(c.L*100, 2), num(c.C, 4), num(c.H, 2))
+}
+
+// Linear converts an sRGB channel to linear light (0-1).
+func Linear(v uint8) float64 {
+	c := unit(v)
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+// Encode converts a linear light value to a clipped sRGB channel. It is the inverse of Linear.
+func Encode(v float64) uint8 {
+	if v <= 0.0031308 {
+		v *= 12.92
+	} else {
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+
+	return channel(v)
+}
+
+// hue returns the hue in degrees of a color from its channels, max channel and chroma.
+func hue(r, g, b, maxC, d float64) float64 {
+	var h float64
+
+	switch maxC {
+	case r:
+		h = math.Mod((g-b)/d, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+
+	return h
+}
+
+// fromChroma returns a color from its hue, chroma and the value to add to each channel.
+func fromChroma(h, chroma, m float64) color.RGBA {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+
+	x := chroma * (1 - math.Abs(math.Mod(h/60, 2)-1))
+
+	var r, g, b float64
+
+	switch {
+	case h < 60:
+		r, g = chroma, x
+	case h < 120:
+		r, g = x, chroma
+	case h < 180:
+		g, b = chroma, x
+	case h < 240:
+		g, b = x, chroma
+	case h < 300:
+		r, b = x, chroma
+	default:
+		r, b = chroma, x
+	}
+
+	return color.RGBA{R: channel(r + m), G: channel(g + m), B: channel(b + m), A: math.MaxUint8}
+}
+
+func unit(v uint8) float64 {
+	return float64(v) / math.MaxUint8
+}
+
+func channel(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(1, v)) * math.MaxUint8))
+}
+
+func round(v float64, decimals int) float64 {
+	p := math.Pow10(decimals)
+
+	return math.Round(v*p) / p
+}
+
+// num formats a number with at most the given decimals, without trailing zeros.
+func num(v float64, decimals int) string {
+	s := fmt.Sprintf("%.*f", decimals, v)
+
+	for s[len(s)-1] == '0' {
+		s = s[:len(s)-1]
+	}
+
+	if s[len(s)-1] == '.' {
+		s = s[:len(s)-1]
+	}
+
+	return s
+}
@@ -0,0 +1,143 @@
+package cover
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+
+	"github.com/mgjules/spoty/dither"
+)
+
+const (
+	// MaxBitmapSize is the maximum width and height of a bitmap, in pixels.
+	MaxBitmapSize = 512
+	// DefaultBitmapSize is the default width and height of a bitmap, in pixels.
+	DefaultBitmapSize = 64
+)
+
+// BitmapFormat represents the encoding of a bitmap.
+type BitmapFormat string
+
+// Formats of a bitmap.
+const (
+	// BitmapPNG is an indexed PNG.
+	BitmapPNG BitmapFormat = "png"
+	// BitmapRaw is the palette indexes packed with dither.BitsPerPixel bits each, most significant bits first.
+	// Every row starts on a new byte.
+	BitmapRaw BitmapFormat = "raw"
+)
+
+// BitmapOptions represents the options used to render a cover as a bitmap quantized to a palette.
+type BitmapOptions struct {
+	// Width is the width of the bitmap, in pixels. Defaults to DefaultBitmapSize.
+	Width int
+	// Height is the height of the bitmap, in pixels. Defaults to DefaultBitmapSize.
+	Height int
+	// Fit tells how the cover is fitted to the bitmap. Defaults to FitCover.
+	Fit Fit
+	// Palette holds the colors of the bitmap. Defaults to black and white.
+	Palette color.Palette
+	// Dither is the dithering algorithm. Defaults to dither.FloydSteinberg.
+	Dither dither.Algorithm
+	// Format is the encoding of the bitmap. Defaults to BitmapPNG.
+	Format BitmapFormat
+}
+
+// WithDefaults returns the options with the defaults of unset ones.
+func (o BitmapOptions) WithDefaults() BitmapOptions {
+	if o.Width == 0 {
+		o.Width = DefaultBitmapSize
+	}
+
+	if o.Height == 0 {
+		o.Height = DefaultBitmapSize
+	}
+
+	if o.Fit == "" {
+		o.Fit = FitCover
+	}
+
+	if len(o.Palette) == 0 {
+		o.Palette = dither.Palettes["bw"]
+	}
+
+	if o.Dither == "" {
+		o.Dither = dither.FloydSteinberg
+	}
+
+	if o.Format == "" {
+		o.Format = BitmapPNG
+	}
+
+	return o
+}
+
+// Validate makes sure the options are valid. Their defaults must be set.
+func (o BitmapOptions) Validate() error {
+	if o.Width < 1 || o.Width > MaxBitmapSize || o.Height < 1 || o.Height > MaxBitmapSize {
+		return fmt.Errorf("width and height must be between 1 and %d", MaxBitmapSize)
+	}
+
+	switch o.Fit {
+	case FitCover, FitContain, FitFill:
+	default:
+		return fmt.Errorf("unknown fit %q", o.Fit)
+	}
+
+	if len(o.Palette) < 2 || len(o.Palette) > dither.MaxColors {
+		return fmt.Errorf("palette must have between 2 and %d colors", dither.MaxColors)
+	}
+
+	if !o.Dither.Valid() {
+		return fmt.Errorf("unknown dithering algorithm %q", o.Dither)
+	}
+
+	switch o.Format {
+	case BitmapPNG, BitmapRaw:
+	default:
+		return fmt.Errorf("unknown format %q", o.Format)
+	}
+
+	return nil
+}
+
+// Key returns a key identifying the options.
+func (o BitmapOptions) Key() string {
+	return fmt.Sprintf("%dx%d_%s_%s_%s_%s", o.Width, o.Height, o.Fit, o.Dither, o.Format, dither.PaletteKey(o.Palette))
+}
+
+// RenderBitmap resizes, quantizes and encodes a cover. The options must have their defaults set.
+func RenderBitmap(img image.Image, opts BitmapOptions) (*Image, error) {
+	quantized := dither.Dither(Resize(img, opts.Width, opts.Height, opts.Fit), opts.Palette, opts.Dither)
+	bounds := quantized.Bounds()
+
+	var (
+		buf         bytes.Buffer
+		contentType string
+		err         error
+	)
+
+	switch opts.Format {
+	case BitmapPNG:
+		contentType = "image/png"
+		err = png.Encode(&buf, quantized)
+	case BitmapRaw:
+		contentType = "application/octet-stream"
+		_, err = buf.Write(dither.Pack(quantized))
+	default:
+		err = fmt.Errorf("unknown format %q", opts.Format)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+
+	return &Image{
+		Data:        buf.Bytes(),
+		ContentType: contentType,
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+	}, nil
+}
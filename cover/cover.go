@@ -0,0 +1,243 @@
+// Package cover resizes and re-encodes cover art for constrained clients.
+package cover
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"math"
+
+	"github.com/nfnt/resize"
+)
+
+const (
+	// DefaultSize is the default width and height of a cover, in pixels.
+	DefaultSize = 300
+	// MaxSize is the maximum width and height of a cover, in pixels.
+	MaxSize = 1024
+	// DefaultQuality is the default quality of JPEG covers.
+	DefaultQuality = 85
+)
+
+// Format represents the encoding of a cover.
+type Format string
+
+// Formats of a cover.
+const (
+	FormatJPEG Format = "jpeg"
+	FormatPNG  Format = "png"
+	// FormatWebP asks for a WebP cover. WebP cannot be encoded with the standard library
+	// so it falls back to PNG, which every WebP-capable client can decode.
+	FormatWebP Format = "webp"
+	// FormatRGB565 is raw 16-bit pixels, row by row, in big-endian order as expected by most
+	// SPI displays.
+	FormatRGB565 Format = "rgb565"
+)
+
+// Fit represents how a cover is fitted to its size.
+type Fit string
+
+// Fits of a cover.
+const (
+	// FitCover crops the cover around its center to fill the size.
+	FitCover Fit = "cover"
+	// FitContain scales the cover to fit in the size, keeping its aspect ratio.
+	FitContain Fit = "contain"
+	// FitFill stretches the cover to the size.
+	FitFill Fit = "fill"
+)
+
+// Options represents the options used to render a cover.
+type Options struct {
+	// Size is the width and height of the cover, in pixels. Defaults to DefaultSize.
+	Size int
+	// Format is the encoding of the cover. Defaults to FormatJPEG.
+	Format Format
+	// Fit tells how the cover is fitted to its size. Defaults to FitCover.
+	Fit Fit
+	// Quality is the quality of JPEG covers (1-100). Defaults to DefaultQuality.
+	Quality int
+}
+
+// WithDefaults returns the options with the defaults of unset ones.
+func (o Options) WithDefaults() Options {
+	if o.Size == 0 {
+		o.Size = DefaultSize
+	}
+
+	if o.Format == "" {
+		o.Format = FormatJPEG
+	}
+
+	if o.Fit == "" {
+		o.Fit = FitCover
+	}
+
+	if o.Quality == 0 {
+		o.Quality = DefaultQuality
+	}
+
+	return o
+}
+
+// Validate makes sure the options are valid. Their defaults must be set.
+func (o Options) Validate() error {
+	if o.Size < 1 || o.Size > MaxSize {
+		return fmt.Errorf("size must be between 1 and %d", MaxSize)
+	}
+
+	switch o.Format {
+	case FormatJPEG, FormatPNG, FormatWebP, FormatRGB565:
+	default:
+		return fmt.Errorf("unknown format %q", o.Format)
+	}
+
+	switch o.Fit {
+	case FitCover, FitContain, FitFill:
+	default:
+		return fmt.Errorf("unknown fit %q", o.Fit)
+	}
+
+	if o.Quality < 1 || o.Quality > 100 {
+		return errors.New("quality must be between 1 and 100")
+	}
+
+	return nil
+}
+
+// Key returns a key identifying the options. The quality is only part of it for JPEG covers.
+func (o Options) Key() string {
+	key := fmt.Sprintf("%d_%s_%s", o.Size, o.Format, o.Fit)
+	if o.Format == FormatJPEG {
+		key += fmt.Sprintf("_%d", o.Quality)
+	}
+
+	return key
+}
+
+// Image represents an encoded cover.
+type Image struct {
+	Data        []byte `json:"-"`
+	ContentType string `json:"content_type"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+}
+
+// ETag returns the strong ETag of encoded image data.
+func ETag(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// Render resizes and encodes a cover. The options must have their defaults set.
+func Render(img image.Image, opts Options) (*Image, error) {
+	resized := Resize(img, opts.Size, opts.Size, opts.Fit)
+	bounds := resized.Bounds()
+
+	var (
+		buf         bytes.Buffer
+		contentType string
+		err         error
+	)
+
+	switch opts.Format {
+	case FormatJPEG:
+		contentType = "image/jpeg"
+		err = jpeg.Encode(&buf, resized, &jpeg.Options{Quality: opts.Quality})
+	case FormatPNG, FormatWebP:
+		contentType = "image/png"
+		err = png.Encode(&buf, resized)
+	case FormatRGB565:
+		contentType = "application/octet-stream"
+		_, err = buf.Write(RGB565(resized))
+	default:
+		err = fmt.Errorf("unknown format %q", opts.Format)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+
+	return &Image{
+		Data:        buf.Bytes(),
+		ContentType: contentType,
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+	}, nil
+}
+
+// Resize fits an image to width x height pixels.
+func Resize(img image.Image, width, height int, fit Fit) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= 0 || h <= 0 {
+		return img
+	}
+
+	switch fit {
+	case FitContain:
+		scale := math.Min(float64(width)/float64(w), float64(height)/float64(h))
+		w = int(math.Max(1, math.Round(float64(w)*scale)))
+		h = int(math.Max(1, math.Round(float64(h)*scale)))
+
+		return resize.Resize(uint(w), uint(h), img, resize.Lanczos3)
+	case FitFill:
+		return resize.Resize(uint(width), uint(height), img, resize.Lanczos3)
+	default:
+		// The largest centered region with the aspect ratio of the result is kept.
+		cw, ch := w, w*height/width
+		if ch > h {
+			cw, ch = h*width/height, h
+		}
+
+		if ch < 1 {
+			ch = 1
+		}
+
+		x, y := b.Min.X+(w-cw)/2, b.Min.Y+(h-ch)/2
+
+		return resize.Resize(uint(width), uint(height), crop(img, image.Rect(x, y, x+cw, y+ch)), resize.Lanczos3)
+	}
+}
+
+// RGB565 returns the pixels of an image as big-endian RGB565, row by row.
+func RGB565(img image.Image) []byte {
+	b := img.Bounds()
+	data := make([]byte, 0, b.Dx()*b.Dy()*2)
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			v := uint16(c.R>>3)<<11 | uint16(c.G>>2)<<5 | uint16(c.B>>3)
+			data = append(data, byte(v>>8), byte(v))
+		}
+	}
+
+	return data
+}
+
+// crop returns the part of an image within r.
+func crop(img image.Image, r image.Rectangle) image.Image {
+	if r == img.Bounds() {
+		return img
+	}
+
+	if sub, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(r)
+	}
+
+	cropped := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(cropped, cropped.Bounds(), img, r.Min, draw.Src)
+
+	return cropped
+}
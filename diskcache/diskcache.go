@@ -0,0 +1,362 @@
+package diskcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mgjules/spoty/config"
+	"github.com/mgjules/spoty/json"
+	"github.com/mgjules/spoty/logger"
+	"go.uber.org/fx"
+)
+
+const (
+	// _evictRatio is the share of the maximum size the cache is brought back to when it is full.
+	_evictRatio = 0.9
+	_tmpPrefix  = ".tmp-"
+)
+
+// Module exported for initialising a new Cache.
+var Module = fx.Options(
+	fx.Provide(New),
+)
+
+// entry is the content of a cache file. The entries of binary payloads are followed by a newline and the payload.
+type entry struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Cache is a durable key-value cache stored as one file per entry in a directory.
+// Files are addressed by the SHA-256 of their key. Their modification time is bumped
+// whenever they are read so that the least recently used ones are evicted first once
+// the cache grows beyond its maximum size.
+//
+// A Cache without a directory is disabled: it never finds nor stores anything.
+type Cache struct {
+	dir      string
+	maxBytes int64
+
+	mu   sync.Mutex
+	size int64
+
+	logger *logger.Logger
+}
+
+// Caches represents the disk caches of the application.
+type Caches struct {
+	// Colors persists the colors of album images.
+	Colors *Cache
+	// Covers persists album images and their resized versions.
+	Covers *Cache
+}
+
+// New opens the configured disk caches.
+func New(cfg *config.Config, logger *logger.Logger) (*Caches, error) {
+	colors, err := Open(cfg.ColorCacheDir, cfg.ColorCacheMaxBytes, logger)
+	if err != nil {
+		return nil, fmt.Errorf("colors: %w", err)
+	}
+
+	covers, err := Open(cfg.CoverCacheDir, cfg.CoverCacheMaxBytes, logger)
+	if err != nil {
+		return nil, fmt.Errorf("covers: %w", err)
+	}
+
+	return &Caches{
+		Colors: colors,
+		Covers: covers,
+	}, nil
+}
+
+// Open opens a Cache in a directory of at most maxBytes, creating it if needed.
+// An empty directory disables the Cache.
+func Open(dir string, maxBytes int64, logger *logger.Logger) (*Cache, error) {
+	c := Cache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		logger:   logger,
+	}
+
+	if c.dir == "" {
+		return &c, nil
+	}
+
+	if c.maxBytes <= 0 {
+		return nil, errors.New("disk cache size must be positive")
+	}
+
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create disk cache directory: %w", err)
+	}
+
+	// Temporary files are only left behind if the process stopped while writing them.
+	leftovers, _ := filepath.Glob(filepath.Join(c.dir, _tmpPrefix+"*"))
+	for _, path := range leftovers {
+		os.Remove(path) //nolint: errcheck
+	}
+
+	files, err := c.files()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan disk cache directory: %w", err)
+	}
+
+	for _, f := range files {
+		c.size += f.size
+	}
+
+	if c.size > c.maxBytes {
+		c.evict(files)
+	}
+
+	return &c, nil
+}
+
+// Enabled tells whether the cache stores anything.
+func (c *Cache) Enabled() bool {
+	return c.dir != ""
+}
+
+// Get decodes the value stored under key into v.
+// It reports whether the value was found.
+func (c *Cache) Get(key string, v any) (bool, error) {
+	_, found, err := c.get(key, v, false)
+
+	return found, err
+}
+
+// GetBytes returns the binary payload stored under key by SetBytes and decodes its metadata into v, if not nil.
+// It reports whether the payload was found.
+func (c *Cache) GetBytes(key string, v any) ([]byte, bool, error) {
+	return c.get(key, v, true)
+}
+
+// get reads the entry stored under key, decodes its value into v, if not nil, and returns its binary payload, if any.
+func (c *Cache) get(key string, v any, payload bool) ([]byte, bool, error) {
+	if !c.Enabled() {
+		return nil, false, nil
+	}
+
+	path := c.path(key)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("read: %w", err)
+	}
+
+	var rest []byte
+	if payload {
+		var found bool
+		if data, rest, found = bytes.Cut(data, []byte("\n")); !found {
+			return nil, false, errors.New("missing payload")
+		}
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, false, fmt.Errorf("unmarshal entry: %w", err)
+	}
+
+	if e.Key != key {
+		return nil, false, nil
+	}
+
+	if v != nil {
+		if err := json.Unmarshal(e.Value, v); err != nil {
+			return nil, false, fmt.Errorf("unmarshal value: %w", err)
+		}
+	}
+
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		c.logger.Warnw("failed to touch disk cache entry", "error", err.Error(), "path", path)
+	}
+
+	return rest, true, nil
+}
+
+// Set stores v under key, evicting the least recently used entries if the cache grows too large.
+func (c *Cache) Set(key string, v any) error {
+	return c.set(key, v, nil)
+}
+
+// SetBytes stores a binary payload as is under key, along with its metadata v, if not nil.
+// It evicts the least recently used entries if the cache grows too large.
+func (c *Cache) SetBytes(key string, v any, payload []byte) error {
+	if payload == nil {
+		payload = []byte{}
+	}
+
+	return c.set(key, v, payload)
+}
+
+// set stores an entry, followed by its binary payload if not nil.
+func (c *Cache) set(key string, v any, payload []byte) error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal value: %w", err)
+	}
+
+	data, err := json.Marshal(entry{Key: key, Value: value})
+	if err != nil {
+		return fmt.Errorf("marshal entry: %w", err)
+	}
+
+	if payload != nil {
+		data = append(append(data, '\n'), payload...)
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+
+	// Entries are written to a temporary file and renamed so that readers never see partial ones.
+	tmp, err := os.CreateTemp(c.dir, _tmpPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint: errcheck
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint: errcheck
+
+		return fmt.Errorf("write: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var previous int64
+	if info, err := os.Stat(path); err == nil {
+		previous = info.Size()
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename: %w", err)
+	}
+
+	c.size += int64(len(data)) - previous
+
+	if c.size > c.maxBytes {
+		files, err := c.files()
+		if err != nil {
+			return fmt.Errorf("scan: %w", err)
+		}
+
+		c.evict(files)
+	}
+
+	return nil
+}
+
+// Size returns the total size of the entries, in bytes.
+func (c *Cache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.size
+}
+
+// path returns the path of the file of an entry.
+// Files are spread over subdirectories named after the first byte of their hash.
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+
+	return filepath.Join(c.dir, name[:2], name)
+}
+
+// file represents a cache file.
+type file struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// files lists the cache files.
+func (c *Cache) files() ([]file, error) {
+	var files []file
+
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		if strings.HasPrefix(d.Name(), _tmpPrefix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		files = append(files, file{path: path, size: info.Size(), modTime: info.ModTime()})
+
+		return nil
+	})
+
+	return files, err
+}
+
+// evict removes the least recently used files until the cache is back under its maximum size.
+// The size of the cache is recomputed from the files.
+func (c *Cache) evict(files []file) {
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	c.size = 0
+	for _, f := range files {
+		c.size += f.size
+	}
+
+	target := int64(float64(c.maxBytes) * _evictRatio)
+
+	var evicted int
+	for _, f := range files {
+		if c.size <= target {
+			break
+		}
+
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			c.logger.Warnw("failed to evict disk cache entry", "error", err.Error(), "path", f.path)
+
+			continue
+		}
+
+		c.size -= f.size
+		evicted++
+	}
+
+	c.logger.Debugw("evicted disk cache entries", "evicted", evicted, "size", c.size)
+}
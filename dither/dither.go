@@ -0,0 +1,273 @@
+// Package dither quantizes images to small palettes for devices such as e-paper displays and LED matrices.
+package dither
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MaxColors is the maximum number of colors of a palette.
+const MaxColors = 256
+
+// Algorithm represents a dithering algorithm.
+type Algorithm string
+
+// Dithering algorithms.
+const (
+	// None maps every pixel to its nearest color.
+	None Algorithm = "none"
+	// FloydSteinberg diffuses the whole quantization error to the neighbouring pixels.
+	FloydSteinberg Algorithm = "floyd-steinberg"
+	// Atkinson diffuses three quarters of the quantization error further away, keeping more contrast.
+	Atkinson Algorithm = "atkinson"
+	// Bayer offsets every pixel with an 8x8 threshold map before mapping it to its nearest color.
+	Bayer Algorithm = "bayer"
+)
+
+// Valid tells whether the algorithm is known.
+func (a Algorithm) Valid() bool {
+	switch a {
+	case None, FloydSteinberg, Atkinson, Bayer:
+		return true
+	default:
+		return false
+	}
+}
+
+// Palettes are the named palettes.
+var Palettes = map[string]color.Palette{
+	// bw is 1-bit black and white.
+	"bw": {
+		color.RGBA{A: 0xff},
+		color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	},
+	// gray4 is 2-bit grayscale.
+	"gray4": {
+		color.RGBA{A: 0xff},
+		color.RGBA{R: 0x55, G: 0x55, B: 0x55, A: 0xff},
+		color.RGBA{R: 0xaa, G: 0xaa, B: 0xaa, A: 0xff},
+		color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	},
+	// bwr is the black, white and red of 3-color e-paper displays.
+	"bwr": {
+		color.RGBA{A: 0xff},
+		color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		color.RGBA{R: 0xff, A: 0xff},
+	},
+}
+
+// ParsePalette returns a named palette or a palette of comma separated hex colors (e.g. "000000,FFFFFF,FF0000").
+func ParsePalette(s string) (color.Palette, error) {
+	if p, ok := Palettes[s]; ok {
+		return p, nil
+	}
+
+	parts := strings.Split(s, ",")
+	if len(parts) < 2 || len(parts) > MaxColors {
+		return nil, fmt.Errorf("palette must be one of bw, gray4 and bwr or a list of 2 to %d hex colors", MaxColors)
+	}
+
+	p := make(color.Palette, 0, len(parts))
+	for _, part := range parts {
+		hex := strings.TrimPrefix(strings.TrimSpace(part), "#")
+		if len(hex) != 6 {
+			return nil, fmt.Errorf("invalid color %q", part)
+		}
+
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid color %q", part)
+		}
+
+		p = append(p, color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff})
+	}
+
+	return p, nil
+}
+
+// PaletteKey returns a key identifying a palette: its colors as uppercase hex, comma separated.
+func PaletteKey(p color.Palette) string {
+	hexes := make([]string, len(p))
+	for i, c := range p {
+		rgba := color.RGBAModel.Convert(c).(color.RGBA)
+		hexes[i] = fmt.Sprintf("%02X%02X%02X", rgba.R, rgba.G, rgba.B)
+	}
+
+	return strings.Join(hexes, ",")
+}
+
+// Dither quantizes an image to a palette using an algorithm.
+// Transparent pixels are composited over black first.
+func Dither(img image.Image, p color.Palette, algo Algorithm) *image.Paletted {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	out := image.NewPaletted(image.Rect(0, 0, w, h), p)
+
+	if w <= 0 || h <= 0 || len(p) == 0 {
+		return out
+	}
+
+	colors := make([][3]float64, len(p))
+	for i, c := range p {
+		rgba := color.RGBAModel.Convert(c).(color.RGBA)
+		colors[i] = [3]float64{float64(rgba.R), float64(rgba.G), float64(rgba.B)}
+	}
+
+	pixels := make([][3]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r, g, bl, _ := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			pixels[y*w+x] = [3]float64{float64(r >> 8), float64(g >> 8), float64(bl >> 8)}
+		}
+	}
+
+	var spread float64
+	if algo == Bayer {
+		spread = paletteSpread(colors)
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			px := pixels[y*w+x]
+
+			if algo == Bayer {
+				offset := spread * ((float64(_bayer[y%8][x%8])+0.5)/64 - 0.5)
+				px = [3]float64{px[0] + offset, px[1] + offset, px[2] + offset}
+			}
+
+			i := nearest(colors, px)
+			out.Pix[y*out.Stride+x] = uint8(i)
+
+			var kernel []diffusion
+			switch algo {
+			case FloydSteinberg:
+				kernel = _floydSteinberg
+			case Atkinson:
+				kernel = _atkinson
+			default:
+				continue
+			}
+
+			errs := [3]float64{px[0] - colors[i][0], px[1] - colors[i][1], px[2] - colors[i][2]}
+			for _, d := range kernel {
+				nx, ny := x+d.dx, y+d.dy
+				if nx < 0 || nx >= w || ny >= h {
+					continue
+				}
+
+				n := &pixels[ny*w+nx]
+				for c := range n {
+					n[c] += errs[c] * d.weight
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+// diffusion represents the share of the quantization error of a pixel given to one of its neighbours.
+type diffusion struct {
+	dx, dy int
+	weight float64
+}
+
+var (
+	_floydSteinberg = []diffusion{
+		{1, 0, 7.0 / 16}, {-1, 1, 3.0 / 16}, {0, 1, 5.0 / 16}, {1, 1, 1.0 / 16},
+	}
+	_atkinson = []diffusion{
+		{1, 0, 1.0 / 8}, {2, 0, 1.0 / 8}, {-1, 1, 1.0 / 8}, {0, 1, 1.0 / 8}, {1, 1, 1.0 / 8}, {0, 2, 1.0 / 8},
+	}
+	_bayer = [8][8]int{
+		{0, 32, 8, 40, 2, 34, 10, 42},
+		{48, 16, 56, 24, 50, 18, 58, 26},
+		{12, 44, 4, 36, 14, 46, 6, 38},
+		{60, 28, 52, 20, 62, 30, 54, 22},
+		{3, 35, 11, 43, 1, 33, 9, 41},
+		{51, 19, 59, 27, 49, 17, 57, 25},
+		{15, 47, 7, 39, 13, 45, 5, 37},
+		{63, 31, 55, 23, 61, 29, 53, 21},
+	}
+)
+
+// nearest returns the index of the color closest to a pixel.
+func nearest(colors [][3]float64, px [3]float64) int {
+	best, bestDist := 0, math.Inf(1)
+
+	for i, c := range colors {
+		dr, dg, db := px[0]-c[0], px[1]-c[1], px[2]-c[2]
+		if dist := dr*dr + dg*dg + db*db; dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+
+	return best
+}
+
+// paletteSpread returns the per channel amplitude of the ordered dithering offsets:
+// the average distance between the colors and their nearest neighbour, per channel.
+// It is 255 for black and white and 85 for 4 grays.
+func paletteSpread(colors [][3]float64) float64 {
+	if len(colors) < 2 {
+		return 0
+	}
+
+	var total float64
+	for i, a := range colors {
+		closest := math.Inf(1)
+
+		for j, b := range colors {
+			if i == j {
+				continue
+			}
+
+			dr, dg, db := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+			closest = math.Min(closest, math.Sqrt(dr*dr+dg*dg+db*db))
+		}
+
+		total += closest
+	}
+
+	return total / float64(len(colors)) / math.Sqrt(3)
+}
+
+// BitsPerPixel returns the number of bits of the packed index of a color of a palette of n colors: 1, 2, 4 or 8.
+func BitsPerPixel(n int) int {
+	switch {
+	case n <= 2:
+		return 1
+	case n <= 4:
+		return 2
+	case n <= 16:
+		return 4
+	default:
+		return 8
+	}
+}
+
+// Pack returns the palette indexes of an image packed with BitsPerPixel bits each, most significant bits first.
+// Every row starts on a new byte.
+func Pack(img *image.Paletted) []byte {
+	b := img.Bounds()
+	bpp := BitsPerPixel(len(img.Palette))
+	perByte := 8 / bpp
+	rowBytes := (b.Dx() + perByte - 1) / perByte
+
+	data := make([]byte, rowBytes*b.Dy())
+	for y := 0; y < b.Dy(); y++ {
+		row := data[y*rowBytes : (y+1)*rowBytes]
+
+		for x := 0; x < b.Dx(); x++ {
+			index := img.ColorIndexAt(b.Min.X+x, b.Min.Y+y)
+			shift := 8 - bpp*(x%perByte+1)
+			row[x/perByte] |= index << shift
+		}
+	}
+
+	return data
+}
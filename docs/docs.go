@@ -1,5 +1,5 @@
-// Package docs GENERATED BY SWAG; DO NOT EDIT
-// This file was generated by swaggo/swag
+// Code generated by swaggo/swag. DO NOT EDIT.
+
 package docs
 
 import "github.com/swaggo/swag"
@@ -48,6 +48,217 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/albums/{id}/images": {
+            "get": {
+                "description": "returns the images of any album along with their colors",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spoty"
+                ],
+                "summary": "Cover Images of an Album",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "spotify ID of the album",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "whether to include the palette (main colors and swatches) of each image",
+                        "name": "palette",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 8,
+                        "description": "maximum number of colors of a palette (1-32)",
+                        "name": "palette_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "ignore colors whose saturation is below it when finding the dominant color (0-1)",
+                        "name": "min_saturation",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "ignore colors whose lightness is below it when finding the dominant color (0-1)",
+                        "name": "min_lightness",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "ignore colors whose lightness is above it when finding the dominant color (0-1)",
+                        "name": "max_lightness",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "favour saturated colors when finding the dominant color (0 disables it)",
+                        "name": "saturation_weight",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "share of each dimension of the cover kept around its center when finding the dominant color (0-1]",
+                        "name": "center_crop",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "share of each dimension of the cover ignored at each border when finding the dominant color (0-0.45)",
+                        "name": "border",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "returns cover images",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/spoty.Image"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "album not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "album could not be retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/analyze": {
+            "post": {
+                "description": "returns the colors of an uploaded JPEG or PNG image, sent either as the \"image\" field of a multipart form or as the raw request body",
+                "consumes": [
+                    "multipart/form-data",
+                    "image/jpeg",
+                    "image/png"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spoty"
+                ],
+                "summary": "Colors of an Uploaded Image",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "image to analyze (multipart requests)",
+                        "name": "image",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "whether to include the palette (main colors and swatches) of the image",
+                        "name": "palette",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 8,
+                        "description": "maximum number of colors of a palette (1-32)",
+                        "name": "palette_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "ignore colors whose saturation is below it when finding the dominant color (0-1)",
+                        "name": "min_saturation",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "ignore colors whose lightness is below it when finding the dominant color (0-1)",
+                        "name": "min_lightness",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "ignore colors whose lightness is above it when finding the dominant color (0-1)",
+                        "name": "max_lightness",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "favour saturated colors when finding the dominant color (0 disables it)",
+                        "name": "saturation_weight",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "share of each dimension of the image kept around its center when finding the dominant color (0-1]",
+                        "name": "center_crop",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "share of each dimension of the image ignored at each border when finding the dominant color (0-0.45)",
+                        "name": "border",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "returns the image colors",
+                        "schema": {
+                            "$ref": "#/definitions/spoty.Image"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid parameters or image",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "413": {
+                        "description": "image too large",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "415": {
+                        "description": "unsupported image type",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
         "/api/authenticate": {
             "get": {
                 "description": "redirects user to spotify for authentication",
@@ -122,21 +333,125 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/colors": {
+            "post": {
+                "description": "returns the images of up to 50 tracks or albums along with their colors; items that could not be processed have an error instead of images",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spoty"
+                ],
+                "summary": "Cover Images of Several Tracks or Albums",
+                "parameters": [
+                    {
+                        "description": "type and spotify IDs of the items",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.colorsRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "whether to include the palette (main colors and swatches) of each image",
+                        "name": "palette",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 8,
+                        "description": "maximum number of colors of a palette (1-32)",
+                        "name": "palette_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "ignore colors whose saturation is below it when finding the dominant color (0-1)",
+                        "name": "min_saturation",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "ignore colors whose lightness is below it when finding the dominant color (0-1)",
+                        "name": "min_lightness",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "ignore colors whose lightness is above it when finding the dominant color (0-1)",
+                        "name": "max_lightness",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "favour saturated colors when finding the dominant color (0 disables it)",
+                        "name": "saturation_weight",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "share of each dimension of the cover kept around its center when finding the dominant color (0-1]",
+                        "name": "center_crop",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "share of each dimension of the cover ignored at each border when finding the dominant color (0-0.45)",
+                        "name": "border",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "returns the images of each item, in the order of the request",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/spoty.ImagesResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "items could not be processed",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
         "/api/current": {
             "get": {
-                "description": "returns information about the current playing track",
+                "description": "returns information about the current playing item (track or podcast episode)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "spoty"
                 ],
-                "summary": "Current Playing Track",
+                "summary": "Current Playing Item",
                 "responses": {
                     "200": {
-                        "description": "returns full track information",
+                        "description": "returns full track information, or the episode information along with its type",
                         "schema": {
-                            "$ref": "#/definitions/spotify.FullTrack"
+                            "$ref": "#/definitions/spoty.CurrentItem"
                         }
                     },
                     "401": {
@@ -146,7 +461,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "no current playing track found",
+                        "description": "no current playing item found",
                         "schema": {
                             "$ref": "#/definitions/http.Error"
                         }
@@ -154,24 +469,93 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/current/images": {
+        "/api/current/bitmap": {
             "get": {
-                "description": "returns the album images of the current playing track",
+                "description": "returns the cover of the current playing item resized and quantized to a palette for e-paper displays and LED matrices; the raw format packs the palette indexes with 1, 2, 4 or 8 bits each (X-Bits-Per-Pixel header), most significant bits first, every row starting on a new byte; the palette is given by the X-Palette header and the dimensions by the X-Image-Width and X-Image-Height headers; supports conditional requests",
                 "produces": [
-                    "application/json"
+                    "image/png",
+                    "application/octet-stream"
                 ],
                 "tags": [
                     "spoty"
                 ],
-                "summary": "Album Images of Current Playing Track",
+                "summary": "Bitmap of Current Playing Item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 64,
+                        "description": "width of the bitmap (1-512)",
+                        "name": "width",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 64,
+                        "description": "height of the bitmap (1-512)",
+                        "name": "height",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "cover",
+                            "contain",
+                            "fill"
+                        ],
+                        "type": "string",
+                        "default": "cover",
+                        "description": "how the cover is fitted to the bitmap: cropped around its center, scaled to fit or stretched",
+                        "name": "fit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "bw",
+                        "description": "bw (black and white), gray4 (4 grays), bwr (black, white and red) or comma separated hex colors",
+                        "name": "palette",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "floyd-steinberg",
+                            "atkinson",
+                            "bayer"
+                        ],
+                        "type": "string",
+                        "default": "floyd-steinberg",
+                        "description": "dithering algorithm",
+                        "name": "dither",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "png",
+                            "raw"
+                        ],
+                        "type": "string",
+                        "default": "png",
+                        "description": "encoding of the bitmap",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "returns album images",
+                        "description": "returns the bitmap",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/spoty.Image"
-                            }
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "bitmap not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
                         }
                     },
                     "401": {
@@ -181,13 +565,13 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "no current playing track found",
+                        "description": "no current playing item or cover found",
                         "schema": {
                             "$ref": "#/definitions/http.Error"
                         }
                     },
                     "500": {
-                        "description": "album images could not be processed",
+                        "description": "cover could not be processed",
                         "schema": {
                             "$ref": "#/definitions/http.Error"
                         }
@@ -195,129 +579,2178 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/version": {
+        "/api/current/cover": {
             "get": {
-                "description": "checks the server's version",
+                "description": "returns the cover of the current playing item resized for constrained clients; the webp format falls back to png and the rgb565 format is raw big-endian pixels, row by row, whose dimensions are given by the X-Image-Width and X-Image-Height headers; supports conditional requests",
                 "produces": [
-                    "application/json"
+                    "image/jpeg",
+                    "image/png",
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "core"
+                    "spoty"
+                ],
+                "summary": "Cover of Current Playing Item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 300,
+                        "description": "width and height of the cover (1-1024)",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "jpeg",
+                            "png",
+                            "webp",
+                            "rgb565"
+                        ],
+                        "type": "string",
+                        "default": "jpeg",
+                        "description": "encoding of the cover",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "cover",
+                            "contain",
+                            "fill"
+                        ],
+                        "type": "string",
+                        "default": "cover",
+                        "description": "how the cover is fitted to its size: cropped around its center, scaled to fit or stretched",
+                        "name": "fit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 85,
+                        "description": "quality of jpeg covers (1-100)",
+                        "name": "quality",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Health Check",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "returns the cover",
                         "schema": {
-                            "$ref": "#/definitions/build.Info"
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "cover not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "no current playing item or cover found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "cover could not be processed",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "build.Info": {
-            "type": "object",
-            "properties": {
-                "dirty_build": {
-                    "type": "boolean"
+        },
+        "/api/current/images": {
+            "get": {
+                "description": "returns the album images of the current playing track or the cover images of the current playing episode",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spoty"
+                ],
+                "summary": "Cover Images of Current Playing Item",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "whether to include the palette (main colors and swatches) of each image",
+                        "name": "palette",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 8,
+                        "description": "maximum number of colors of a palette (1-32)",
+                        "name": "palette_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "ignore colors whose saturation is below it when finding the dominant color (0-1)",
+                        "name": "min_saturation",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "ignore colors whose lightness is below it when finding the dominant color (0-1)",
+                        "name": "min_lightness",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "ignore colors whose lightness is above it when finding the dominant color (0-1)",
+                        "name": "max_lightness",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "favour saturated colors when finding the dominant color (0 disables it)",
+                        "name": "saturation_weight",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "share of each dimension of the cover kept around its center when finding the dominant color (0-1]",
+                        "name": "center_crop",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "share of each dimension of the cover ignored at each border when finding the dominant color (0-0.45)",
+                        "name": "border",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "returns cover images",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/spoty.Image"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "no current playing item found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "album images could not be processed",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/current/scheme": {
+            "get": {
+                "description": "returns a Material-style color scheme (tonal palettes and light/dark roles) generated from the dominant color of the current playing item's cover",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spoty"
+                ],
+                "summary": "Color Scheme of Current Playing Item",
+                "responses": {
+                    "200": {
+                        "description": "returns the color scheme",
+                        "schema": {
+                            "$ref": "#/definitions/scheme.Scheme"
+                        }
+                    },
+                    "401": {
+                        "description": "not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "no current playing item found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "cover images could not be processed",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/current/theme.css": {
+            "get": {
+                "description": "returns a stylesheet declaring the colors of the current playing item's cover as CSS custom properties (--spoty-primary, --spoty-on-primary, --spoty-vibrant, ...); supports conditional requests",
+                "produces": [
+                    "text/css"
+                ],
+                "tags": [
+                    "spoty"
+                ],
+                "summary": "Theme of Current Playing Item",
+                "responses": {
+                    "200": {
+                        "description": "returns the stylesheet",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "stylesheet not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "no current playing item found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "cover images could not be processed",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/history": {
+            "get": {
+                "description": "returns the locally recorded listening history, most recent first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spoty"
+                ],
+                "summary": "Listening History",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "RFC3339 time; returns plays started at or after it",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 time; returns plays started before it",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "maximum number of plays to return (1-500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor returned as next by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "returns a page of plays",
+                        "schema": {
+                            "$ref": "#/definitions/history.Page"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "history could not be retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/history/export": {
+            "get": {
+                "description": "streams the locally recorded listening history, oldest first, as CSV, NDJSON, JSON or a ListenBrainz import",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "spoty"
+                ],
+                "summary": "Export Listening History",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "RFC3339 time; exports plays started at or after it",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 time; exports plays started before it",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "json",
+                            "listenbrainz"
+                        ],
+                        "type": "string",
+                        "default": "ndjson",
+                        "description": "export format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "returns the exported plays",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/player": {
+            "get": {
+                "description": "returns the full playback state including the current track, device, context and dominant colors",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spoty"
+                ],
+                "summary": "Current Playback State",
+                "responses": {
+                    "200": {
+                        "description": "returns playback state",
+                        "schema": {
+                            "$ref": "#/definitions/spoty.PlaybackState"
+                        }
+                    },
+                    "401": {
+                        "description": "not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "no active playback found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/recent": {
+            "get": {
+                "description": "returns the recently played tracks using cursor-based pagination",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spoty"
+                ],
+                "summary": "Recently Played Tracks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "maximum number of items to return (1-50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "unix timestamp in milliseconds; returns items played before it",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "unix timestamp in milliseconds; returns items played after it",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "whether to include the dominant colors of each item's album images",
+                        "name": "colors",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "returns recently played tracks",
+                        "schema": {
+                            "$ref": "#/definitions/spoty.RecentlyPlayed"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "recently played tracks could not be retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sessions": {
+            "get": {
+                "description": "returns the listening sessions (continuous listening separated by breaks), most recent first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spoty"
+                ],
+                "summary": "Listening Sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "RFC3339 time; only considers plays started at or after it",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 time; only considers plays started before it",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "20m",
+                        "description": "break after which a new session starts (e.g. 30m)",
+                        "name": "gap",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "maximum number of sessions to return (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor returned as next by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "returns a page of sessions",
+                        "schema": {
+                            "$ref": "#/definitions/history.SessionPage"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "sessions could not be retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/stats": {
+            "get": {
+                "description": "returns top tracks, artists, albums and genres, minutes listened, streaks and listening distributions over a time window\ndistributions are computed from hourly UTC rollups: in time zones whose offset is not a whole number of hours (e.g. Asia/Kolkata), plays are counted in the local hour their UTC hour starts in",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spoty"
+                ],
+                "summary": "Listening Statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "RFC3339 time; only considers plays started at or after it",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 time; only considers plays started before it (defaults to now)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "maximum number of top items to return (1-50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "UTC",
+                        "description": "IANA time zone used for distributions and streaks",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "returns listening statistics",
+                        "schema": {
+                            "$ref": "#/definitions/history.Stats"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "statistics could not be computed",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tracks/{id}/images": {
+            "get": {
+                "description": "returns the album images of any track along with their colors",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spoty"
+                ],
+                "summary": "Cover Images of a Track",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "spotify ID of the track",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "whether to include the palette (main colors and swatches) of each image",
+                        "name": "palette",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 8,
+                        "description": "maximum number of colors of a palette (1-32)",
+                        "name": "palette_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "ignore colors whose saturation is below it when finding the dominant color (0-1)",
+                        "name": "min_saturation",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "ignore colors whose lightness is below it when finding the dominant color (0-1)",
+                        "name": "min_lightness",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "ignore colors whose lightness is above it when finding the dominant color (0-1)",
+                        "name": "max_lightness",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "favour saturated colors when finding the dominant color (0 disables it)",
+                        "name": "saturation_weight",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "share of each dimension of the cover kept around its center when finding the dominant color (0-1]",
+                        "name": "center_crop",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "share of each dimension of the cover ignored at each border when finding the dominant color (0-0.45)",
+                        "name": "border",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "returns cover images",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/spoty.Image"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "track not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "track could not be retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/version": {
+            "get": {
+                "description": "checks the server's version",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "core"
+                ],
+                "summary": "Health Check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/build.Info"
+                        }
+                    }
+                }
+            }
+        },
+        "/card/now-playing.png": {
+            "get": {
+                "description": "returns a PNG card of the current playing item (title, artists, progress bar and cover) on a gradient of the colors of its cover, for places which do not accept SVG images such as chat previews; shows the last played track when nothing is playing; supports conditional requests",
+                "produces": [
+                    "image/png"
+                ],
+                "tags": [
+                    "cards"
+                ],
+                "summary": "Now Playing PNG Card",
+                "parameters": [
+                    {
+                        "enum": [
+                            "small",
+                            "og",
+                            "square",
+                            "story"
+                        ],
+                        "type": "string",
+                        "default": "og",
+                        "description": "size of the card: small (600x200), og (1200x630), square (1080x1080) or story (1080x1920)",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "returns the card",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "card not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "card could not be rendered",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/card/now-playing.svg": {
+            "get": {
+                "description": "returns an SVG card of the current playing item (title, artists, progress bar and cover) themed with the dominant color of its cover, meant to be embedded in READMEs and status pages; shows the last played track when nothing is playing; supports conditional requests",
+                "produces": [
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "cards"
+                ],
+                "summary": "Now Playing Card",
+                "parameters": [
+                    {
+                        "enum": [
+                            "compact",
+                            "classic",
+                            "cover"
+                        ],
+                        "type": "string",
+                        "default": "classic",
+                        "description": "layout of the card: a small badge, a banner or a tile with a large cover",
+                        "name": "layout",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "returns the card",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "card not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "card could not be rendered",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/recent.atom": {
+            "get": {
+                "description": "returns the most recent plays as an Atom feed; supports conditional requests",
+                "produces": [
+                    "application/atom+xml"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Recent Listens Atom Feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "feed token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "returns the Atom feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "feed not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid feed token",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "feed disabled",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "history could not be retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/recent.rss": {
+            "get": {
+                "description": "returns the most recent plays as an RSS feed; supports conditional requests",
+                "produces": [
+                    "application/rss+xml"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Recent Listens RSS Feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "feed token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "returns the RSS feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "feed not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid feed token",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "feed disabled",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "history could not be retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "build.Info": {
+            "type": "object",
+            "properties": {
+                "dirty_build": {
+                    "type": "boolean"
+                },
+                "last_commit": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "string"
+                }
+            }
+        },
+        "color.RGBA": {
+            "type": "object",
+            "properties": {
+                "r": {
+                    "type": "integer"
+                }
+            }
+        },
+        "colors.HSL": {
+            "type": "object",
+            "properties": {
+                "h": {
+                    "type": "number"
+                },
+                "l": {
+                    "type": "number"
+                },
+                "s": {
+                    "type": "number"
+                }
+            }
+        },
+        "colors.HSV": {
+            "type": "object",
+            "properties": {
+                "h": {
+                    "type": "number"
+                },
+                "s": {
+                    "type": "number"
+                },
+                "v": {
+                    "type": "number"
+                }
+            }
+        },
+        "colors.OKLCH": {
+            "type": "object",
+            "properties": {
+                "c": {
+                    "type": "number"
+                },
+                "h": {
+                    "type": "number"
+                },
+                "l": {
+                    "type": "number"
+                }
+            }
+        },
+        "colors.OKLab": {
+            "type": "object",
+            "properties": {
+                "a": {
+                    "type": "number"
+                },
+                "b": {
+                    "type": "number"
+                },
+                "l": {
+                    "type": "number"
+                }
+            }
+        },
+        "health.AvailabilityStatus": {
+            "type": "string",
+            "enum": [
+                "unknown",
+                "up",
+                "down"
+            ],
+            "x-enum-varnames": [
+                "StatusUnknown",
+                "StatusUp",
+                "StatusDown"
+            ]
+        },
+        "health.CheckResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error contains the check error message, if the check failed.",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status is the availability status of a component.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/health.AvailabilityStatus"
+                        }
+                    ]
+                },
+                "timestamp": {
+                    "description": "Timestamp holds the time when the check was executed.",
+                    "type": "string"
+                }
+            }
+        },
+        "health.CheckerResult": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "description": "Details contains health information for all checked components.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.CheckResult"
+                    }
+                },
+                "status": {
+                    "description": "Status is the aggregated system availability status.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/health.AvailabilityStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "history.Album": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "history.Artist": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "history.Context": {
+            "type": "object",
+            "properties": {
+                "type": {
+                    "type": "string"
+                },
+                "uri": {
+                    "type": "string"
+                }
+            }
+        },
+        "history.Device": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "history.Outcome": {
+            "type": "string",
+            "enum": [
+                "completed",
+                "skipped_early",
+                "skipped_late"
+            ],
+            "x-enum-varnames": [
+                "OutcomeCompleted",
+                "OutcomeSkippedEarly",
+                "OutcomeSkippedLate"
+            ]
+        },
+        "history.Page": {
+            "type": "object",
+            "properties": {
+                "next": {
+                    "type": "string"
+                },
+                "plays": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/history.Play"
+                    }
+                }
+            }
+        },
+        "history.Play": {
+            "type": "object",
+            "properties": {
+                "album": {
+                    "$ref": "#/definitions/history.Album"
+                },
+                "artists": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/history.Artist"
+                    }
+                },
+                "color": {
+                    "type": "string"
+                },
+                "context": {
+                    "$ref": "#/definitions/history.Context"
+                },
+                "device": {
+                    "$ref": "#/definitions/history.Device"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "ended_at": {
+                    "type": "string"
+                },
+                "genres": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "image_url": {
+                    "type": "string"
+                },
+                "item_id": {
+                    "type": "string"
+                },
+                "item_type": {
+                    "type": "string"
+                },
+                "listened_ms": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "$ref": "#/definitions/history.Outcome"
+                },
+                "repeat": {
+                    "description": "Repeat tells whether the play replays the item of the previous one.",
+                    "type": "boolean"
+                },
+                "started_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "history.Session": {
+            "type": "object",
+            "properties": {
+                "device": {
+                    "$ref": "#/definitions/history.Device"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "ended_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "listened_ms": {
+                    "type": "integer"
+                },
+                "palette": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "tracks": {
+                    "type": "integer"
+                }
+            }
+        },
+        "history.SessionPage": {
+            "type": "object",
+            "properties": {
+                "next": {
+                    "type": "string"
+                },
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/history.Session"
+                    }
+                }
+            }
+        },
+        "history.Stats": {
+            "type": "object",
+            "properties": {
+                "day_of_week": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "from": {
+                    "type": "string"
+                },
+                "hour_of_day": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "minutes_listened": {
+                    "type": "number"
+                },
+                "plays": {
+                    "type": "integer"
+                },
+                "streaks": {
+                    "$ref": "#/definitions/history.Streaks"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "top_albums": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/history.Top"
+                    }
+                },
+                "top_artists": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/history.Top"
+                    }
+                },
+                "top_genres": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/history.Top"
+                    }
+                },
+                "top_tracks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/history.Top"
+                    }
+                }
+            }
+        },
+        "history.Streaks": {
+            "type": "object",
+            "properties": {
+                "current": {
+                    "type": "integer"
+                },
+                "longest": {
+                    "type": "integer"
+                },
+                "longest_end": {
+                    "type": "string"
+                },
+                "longest_start": {
+                    "type": "string"
+                }
+            }
+        },
+        "history.Top": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "minutes": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "plays": {
+                    "type": "integer"
+                }
+            }
+        },
+        "http.Error": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "description": "A human-readable explanation specific to this\noccurrence of the problem.",
+                    "type": "string"
+                },
+                "extensions": {
+                    "description": "Extensions contains additional data.",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "instance": {
+                    "description": "A URI reference that identifies the specific\noccurrence of the problem.  It may or may not yield further\ninformation if dereferenced.",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "The HTTP status code ([RFC7231], Section 6)\ngenerated by the origin server for this occurrence of the problem.",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "A short, human-readable summary of the problem\ntype.  It SHOULD NOT change from occurrence to occurrence of the\nproblem, except for purposes of localization (e.g., using\nproactive content negotiation; see [RFC7231], Section 3.4).",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "A URI reference [RFC3986] that identifies the\nproblem type.  This specification encourages that, when\ndereferenced, it provide human-readable documentation for the\nproblem type (e.g., using HTML [W3C.REC-html5-20141028]).  When\nthis member is not present, its value is assumed to be\n\"about:blank\".",
+                    "type": "string"
+                }
+            }
+        },
+        "http.Success": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "http.colorsRequest": {
+            "type": "object",
+            "required": [
+                "ids"
+            ],
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string",
+                    "default": "track",
+                    "enum": [
+                        "track",
+                        "album"
+                    ]
+                }
+            }
+        },
+        "palette.Color": {
+            "type": "object",
+            "properties": {
+                "contrast": {
+                    "$ref": "#/definitions/palette.Contrast"
+                },
+                "hex": {
+                    "type": "string"
+                },
+                "hsl": {
+                    "$ref": "#/definitions/colors.HSL"
+                },
+                "hsv": {
+                    "$ref": "#/definitions/colors.HSV"
+                },
+                "oklab": {
+                    "$ref": "#/definitions/colors.OKLab"
+                },
+                "oklch": {
+                    "$ref": "#/definitions/colors.OKLCH"
+                },
+                "population": {
+                    "description": "Population is the share of the sampled pixels represented by the color (0-1).",
+                    "type": "number"
+                },
+                "rgba": {
+                    "$ref": "#/definitions/color.RGBA"
+                }
+            }
+        },
+        "palette.Contrast": {
+            "type": "object",
+            "properties": {
+                "black_ratio": {
+                    "description": "BlackRatio is the contrast ratio with black.",
+                    "type": "number"
+                },
+                "classification": {
+                    "description": "Classification is either light or dark.",
+                    "type": "string"
+                },
+                "luminance": {
+                    "description": "Luminance is the WCAG relative luminance of the color (0-1).",
+                    "type": "number"
+                },
+                "secondary_text": {
+                    "description": "SecondaryText is the text color made as translucent as possible while meeting WCAG AA\n(or keeping the contrast of the text if it does not), flattened on the background.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/palette.Foreground"
+                        }
+                    ]
+                },
+                "text": {
+                    "description": "Text is the recommended color of text: black or white, whichever contrasts the most.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/palette.Foreground"
+                        }
+                    ]
+                },
+                "white_ratio": {
+                    "description": "WhiteRatio is the contrast ratio with white.",
+                    "type": "number"
+                }
+            }
+        },
+        "palette.Foreground": {
+            "type": "object",
+            "properties": {
+                "aa": {
+                    "description": "AA tells whether the contrast meets WCAG AA for normal text (\u003e= 4.5).",
+                    "type": "boolean"
+                },
+                "aa_large": {
+                    "description": "AALarge tells whether the contrast meets WCAG AA for large text (\u003e= 3).",
+                    "type": "boolean"
+                },
+                "aaa": {
+                    "description": "AAA tells whether the contrast meets WCAG AAA for normal text (\u003e= 7).",
+                    "type": "boolean"
+                },
+                "hex": {
+                    "type": "string"
+                },
+                "ratio": {
+                    "type": "number"
+                },
+                "rgba": {
+                    "$ref": "#/definitions/color.RGBA"
+                }
+            }
+        },
+        "palette.Palette": {
+            "type": "object",
+            "properties": {
+                "colors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/palette.Color"
+                    }
+                },
+                "swatches": {
+                    "$ref": "#/definitions/palette.Swatches"
+                }
+            }
+        },
+        "palette.Swatches": {
+            "type": "object",
+            "properties": {
+                "dark_muted": {
+                    "$ref": "#/definitions/palette.Color"
+                },
+                "dark_vibrant": {
+                    "$ref": "#/definitions/palette.Color"
+                },
+                "light_muted": {
+                    "$ref": "#/definitions/palette.Color"
+                },
+                "light_vibrant": {
+                    "$ref": "#/definitions/palette.Color"
+                },
+                "muted": {
+                    "$ref": "#/definitions/palette.Color"
+                },
+                "vibrant": {
+                    "$ref": "#/definitions/palette.Color"
+                }
+            }
+        },
+        "scheme.Palettes": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/scheme.TonalPalette"
+                },
+                "neutral": {
+                    "$ref": "#/definitions/scheme.TonalPalette"
+                },
+                "neutral_variant": {
+                    "$ref": "#/definitions/scheme.TonalPalette"
+                },
+                "primary": {
+                    "$ref": "#/definitions/scheme.TonalPalette"
+                },
+                "secondary": {
+                    "$ref": "#/definitions/scheme.TonalPalette"
+                },
+                "tertiary": {
+                    "$ref": "#/definitions/scheme.TonalPalette"
+                }
+            }
+        },
+        "scheme.Roles": {
+            "type": "object",
+            "properties": {
+                "background": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "error_container": {
+                    "type": "string"
+                },
+                "inverse_on_surface": {
+                    "type": "string"
+                },
+                "inverse_primary": {
+                    "type": "string"
+                },
+                "inverse_surface": {
+                    "type": "string"
+                },
+                "on_background": {
+                    "type": "string"
+                },
+                "on_error": {
+                    "type": "string"
+                },
+                "on_error_container": {
+                    "type": "string"
+                },
+                "on_primary": {
+                    "type": "string"
+                },
+                "on_primary_container": {
+                    "type": "string"
+                },
+                "on_secondary": {
+                    "type": "string"
+                },
+                "on_secondary_container": {
+                    "type": "string"
+                },
+                "on_surface": {
+                    "type": "string"
+                },
+                "on_surface_variant": {
+                    "type": "string"
+                },
+                "on_tertiary": {
+                    "type": "string"
+                },
+                "on_tertiary_container": {
+                    "type": "string"
+                },
+                "outline": {
+                    "type": "string"
+                },
+                "outline_variant": {
+                    "type": "string"
+                },
+                "primary": {
+                    "type": "string"
+                },
+                "primary_container": {
+                    "type": "string"
+                },
+                "scrim": {
+                    "type": "string"
+                },
+                "secondary": {
+                    "type": "string"
+                },
+                "secondary_container": {
+                    "type": "string"
+                },
+                "shadow": {
+                    "type": "string"
+                },
+                "surface": {
+                    "type": "string"
+                },
+                "surface_variant": {
+                    "type": "string"
+                },
+                "tertiary": {
+                    "type": "string"
+                },
+                "tertiary_container": {
+                    "type": "string"
+                }
+            }
+        },
+        "scheme.Scheme": {
+            "type": "object",
+            "properties": {
+                "dark": {
+                    "$ref": "#/definitions/scheme.Roles"
+                },
+                "light": {
+                    "$ref": "#/definitions/scheme.Roles"
+                },
+                "palettes": {
+                    "$ref": "#/definitions/scheme.Palettes"
+                },
+                "seed": {
+                    "description": "Seed is the hex color the scheme was generated from.",
+                    "type": "string"
+                }
+            }
+        },
+        "scheme.TonalPalette": {
+            "type": "object",
+            "properties": {
+                "chroma": {
+                    "description": "Chroma is the OKLCH chroma of the key color. Tones may have less to fit in sRGB.",
+                    "type": "number"
+                },
+                "hue": {
+                    "description": "Hue is the OKLCH hue of the key color (0-360).",
+                    "type": "number"
+                },
+                "tones": {
+                    "description": "Tones maps each standard tone to its hex color.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "spotify.Copyright": {
+            "type": "object",
+            "properties": {
+                "text": {
+                    "description": "The copyright text for the album.",
+                    "type": "string"
                 },
-                "last_commit": {
+                "type": {
+                    "description": "The type of copyright.",
+                    "type": "string"
+                }
+            }
+        },
+        "spotify.EpisodePage": {
+            "type": "object",
+            "properties": {
+                "audio_preview_url": {
+                    "description": "A URL to a 30 second preview (MP3 format) of the episode.",
                     "type": "string"
                 },
-                "revision": {
+                "description": {
+                    "description": "A description of the episode.",
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "description": "The episode length in milliseconds.",
+                    "type": "integer"
+                },
+                "explicit": {
+                    "description": "Whether or not the episode has explicit content\n(true = yes it does; false = no it does not OR unknown).",
+                    "type": "boolean"
+                },
+                "external_urls": {
+                    "description": "External URLs for this episode.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "href": {
+                    "description": "A link to the Web API endpoint providing full details of the episode.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "The Spotify ID for the episode.",
+                    "type": "string"
+                },
+                "images": {
+                    "description": "The cover art for the episode in various sizes, widest first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/spotify.Image"
+                    }
+                },
+                "is_externally_hosted": {
+                    "description": "True if the episode is hosted outside of Spotify’s CDN.",
+                    "type": "boolean"
+                },
+                "is_playable": {
+                    "description": "True if the episode is playable in the given market.\nOtherwise false.",
+                    "type": "boolean"
+                },
+                "languages": {
+                    "description": "A list of the languages used in the episode, identified by their ISO 639 code.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "The name of the episode.",
+                    "type": "string"
+                },
+                "release_date": {
+                    "description": "The date the episode was first released, for example\n\"1981-12-15\". Depending on the precision, it might\nbe shown as \"1981\" or \"1981-12\".",
+                    "type": "string"
+                },
+                "release_date_precision": {
+                    "description": "The precision with which release_date value is known:\n\"year\", \"month\", or \"day\".",
+                    "type": "string"
+                },
+                "resume_point": {
+                    "description": "The user’s most recent position in the episode. Set if the\nsupplied access token is a user token and has the scope\nuser-read-playback-position.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/spotify.ResumePointObject"
+                        }
+                    ]
+                },
+                "show": {
+                    "description": "The show on which the episode belongs.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/spotify.SimpleShow"
+                        }
+                    ]
+                },
+                "type": {
+                    "description": "The object type: \"episode\".",
+                    "type": "string"
+                },
+                "uri": {
+                    "description": "The Spotify URI for the episode.",
                     "type": "string"
                 }
             }
         },
-        "color.RGBA": {
+        "spotify.FullTrack": {
             "type": "object",
             "properties": {
-                "r": {
+                "album": {
+                    "description": "The album on which the track appears. The album object includes a link in href to full information about the album.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/spotify.SimpleAlbum"
+                        }
+                    ]
+                },
+                "artists": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/spotify.SimpleArtist"
+                    }
+                },
+                "available_markets": {
+                    "description": "A list of the countries in which the track can be played,\nidentified by their ISO 3166-1 alpha-2 codes.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "disc_number": {
+                    "description": "The disc number (usually 1 unless the album consists of more than one disc).",
+                    "type": "integer"
+                },
+                "duration_ms": {
+                    "description": "The length of the track, in milliseconds.",
+                    "type": "integer"
+                },
+                "explicit": {
+                    "description": "Whether or not the track has explicit lyrics.\ntrue =\u003e yes, it does; false =\u003e no, it does not.",
+                    "type": "boolean"
+                },
+                "external_ids": {
+                    "description": "Known external IDs for the track.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "external_urls": {
+                    "description": "External URLs for this track.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "href": {
+                    "description": "A link to the Web API endpoint providing full details for this track.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_playable": {
+                    "description": "IsPlayable defines if the track is playable. It's reported when the \"market\" parameter is passed to the tracks\nlisting API.\nSee: https://developer.spotify.com/documentation/general/guides/track-relinking-guide/",
+                    "type": "boolean"
+                },
+                "linked_from": {
+                    "description": "LinkedFrom points to the linked track. It's reported when the \"market\" parameter is passed to the tracks listing\nAPI.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/spotify.LinkedFromInfo"
+                        }
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                },
+                "popularity": {
+                    "description": "Popularity of the track.  The value will be between 0 and 100,\nwith 100 being the most popular.  The popularity is calculated from\nboth total plays and most recent plays.",
+                    "type": "integer"
+                },
+                "preview_url": {
+                    "description": "A URL to a 30 second preview (MP3) of the track.",
+                    "type": "string"
+                },
+                "track_number": {
+                    "description": "The number of the track.  If an album has several\ndiscs, the track number is the number on the specified\nDiscNumber.",
                     "type": "integer"
+                },
+                "type": {
+                    "description": "Type of the track",
+                    "type": "string"
+                },
+                "uri": {
+                    "type": "string"
                 }
             }
         },
-        "health.CheckResult": {
+        "spotify.Image": {
             "type": "object",
             "properties": {
-                "error": {
-                    "description": "Error contains the check error message, if the check failed.",
+                "height": {
+                    "description": "The image height, in pixels.",
+                    "type": "integer"
+                },
+                "url": {
+                    "description": "The source URL of the image.",
+                    "type": "string"
+                },
+                "width": {
+                    "description": "The image width, in pixels.",
+                    "type": "integer"
+                }
+            }
+        },
+        "spotify.LinkedFromInfo": {
+            "type": "object",
+            "properties": {
+                "external_urls": {
+                    "description": "ExternalURLs are the known external APIs for this track or album",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "href": {
+                    "description": "Href is a link to the Web API endpoint providing full details",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID of the linked track",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type of the link: album of the track",
+                    "type": "string"
+                },
+                "uri": {
+                    "description": "URI is the Spotify URI of the track/album",
+                    "type": "string"
+                }
+            }
+        },
+        "spotify.ResumePointObject": {
+            "type": "object",
+            "properties": {
+                "fully_played": {
+                    "description": "Whether or not the episode has been fully played by the user.",
+                    "type": "boolean"
+                },
+                "resume_position_ms": {
+                    "description": "The user’s most recent position in the episode in milliseconds.",
+                    "type": "integer"
+                }
+            }
+        },
+        "spotify.SimpleAlbum": {
+            "type": "object",
+            "properties": {
+                "album_group": {
+                    "description": "The field is present when getting an artist’s\nalbums. Possible values are “album”, “single”,\n“compilation”, “appears_on”. Compare to album_type\nthis field represents relationship between the artist\nand the album.",
+                    "type": "string"
+                },
+                "album_type": {
+                    "description": "The type of the album: one of \"album\",\n\"single\", or \"compilation\".",
+                    "type": "string"
+                },
+                "artists": {
+                    "description": "A slice of SimpleArtists",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/spotify.SimpleArtist"
+                    }
+                },
+                "available_markets": {
+                    "description": "The markets in which the album is available,\nidentified using ISO 3166-1 alpha-2 country\ncodes.  Note that al album is considered\navailable in a market when at least 1 of its\ntracks is available in that market.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "external_urls": {
+                    "description": "Known external URLs for this album.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "href": {
+                    "description": "A link to the Web API enpoint providing full\ndetails of the album.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "The SpotifyID for the album.",
+                    "type": "string"
+                },
+                "images": {
+                    "description": "The cover art for the album in various sizes,\nwidest first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/spotify.Image"
+                    }
+                },
+                "name": {
+                    "description": "The name of the album.",
+                    "type": "string"
+                },
+                "release_date": {
+                    "description": "The date the album was first released.  For example, \"1981-12-15\".\nDepending on the ReleaseDatePrecision, it might be shown as\n\"1981\" or \"1981-12\". You can use ReleaseDateTime to convert this\nto a time.Time value.",
                     "type": "string"
                 },
-                "status": {
-                    "description": "Status is the availability status of a component.",
+                "release_date_precision": {
+                    "description": "The precision with which ReleaseDate value is known: \"year\", \"month\", or \"day\"",
                     "type": "string"
                 },
-                "timestamp": {
-                    "description": "Timestamp holds the time when the check was executed.",
+                "uri": {
+                    "description": "The SpotifyURI for the album.",
                     "type": "string"
                 }
             }
         },
-        "health.CheckerResult": {
+        "spotify.SimpleArtist": {
             "type": "object",
             "properties": {
-                "details": {
-                    "description": "Details contains health information for all checked components.",
+                "external_urls": {
                     "type": "object",
                     "additionalProperties": {
-                        "$ref": "#/definitions/health.CheckResult"
+                        "type": "string"
                     }
                 },
-                "status": {
-                    "description": "Status is the aggregated system availability status.",
+                "href": {
+                    "description": "A link to the Web API enpoint providing full details of the artist.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "uri": {
+                    "description": "The Spotify URI for the artist.",
                     "type": "string"
                 }
             }
         },
-        "http.Error": {
+        "spotify.SimpleShow": {
             "type": "object",
             "properties": {
-                "detail": {
-                    "description": "A human-readable explanation specific to this\noccurrence of the problem.",
+                "available_markets": {
+                    "description": "A list of the countries in which the show can be played,\nidentified by their ISO 3166-1 alpha-2 code.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "copyrights": {
+                    "description": "The copyright statements of the show.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/spotify.Copyright"
+                    }
+                },
+                "description": {
+                    "description": "A description of the show.",
                     "type": "string"
                 },
-                "extensions": {
-                    "description": "Extensions contains additional data.",
+                "explicit": {
+                    "description": "Whether or not the show has explicit content\n(true = yes it does; false = no it does not OR unknown).",
+                    "type": "boolean"
+                },
+                "external_urls": {
+                    "description": "Known external URLs for this show.",
                     "type": "object",
                     "additionalProperties": {
-                        "type": "any"
+                        "type": "string"
                     }
                 },
-                "instance": {
-                    "description": "A URI reference that identifies the specific\noccurrence of the problem.  It may or may not yield further\ninformation if dereferenced.",
+                "href": {
+                    "description": "A link to the Web API endpoint providing full details\nof the show.",
                     "type": "string"
                 },
-                "status": {
-                    "description": "The HTTP status code ([RFC7231], Section 6)\ngenerated by the origin server for this occurrence of the problem.",
-                    "type": "integer"
+                "id": {
+                    "description": "The SpotifyID for the show.",
+                    "type": "string"
                 },
-                "title": {
-                    "description": "A short, human-readable summary of the problem\ntype.  It SHOULD NOT change from occurrence to occurrence of the\nproblem, except for purposes of localization (e.g., using\nproactive content negotiation; see [RFC7231], Section 3.4).",
+                "images": {
+                    "description": "The cover art for the show in various sizes,\nwidest first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/spotify.Image"
+                    }
+                },
+                "is_externally_hosted": {
+                    "description": "True if all of the show’s episodes are hosted outside\nof Spotify’s CDN. This field might be null in some cases.",
+                    "type": "boolean"
+                },
+                "languages": {
+                    "description": "A list of the languages used in the show, identified by\ntheir ISO 639 code.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "media_type": {
+                    "description": "The media type of the show.",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "The name of the show.",
+                    "type": "string"
+                },
+                "publisher": {
+                    "description": "The publisher of the show.",
                     "type": "string"
                 },
                 "type": {
-                    "description": "A URI reference [RFC3986] that identifies the\nproblem type.  This specification encourages that, when\ndereferenced, it provide human-readable documentation for the\nproblem type (e.g., using HTML [W3C.REC-html5-20141028]).  When\nthis member is not present, its value is assumed to be\n\"about:blank\".",
+                    "description": "The object type: “show”.",
                     "type": "string"
-                }
-            }
-        },
-        "http.Success": {
-            "type": "object",
-            "properties": {
-                "message": {
+                },
+                "uri": {
+                    "description": "The Spotify URI for the show.",
                     "type": "string"
                 }
             }
         },
-        "spotify.FullTrack": {
+        "spoty.CurrentItem": {
             "type": "object",
             "properties": {
                 "album": {
                     "description": "The album on which the track appears. The album object includes a link in href to full information about the album.",
-                    "$ref": "#/definitions/spotify.SimpleAlbum"
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/spotify.SimpleAlbum"
+                        }
+                    ]
                 },
                 "artists": {
                     "type": "array",
@@ -340,6 +2773,9 @@ const docTemplate = `{
                     "description": "The length of the track, in milliseconds.",
                     "type": "integer"
                 },
+                "episode": {
+                    "$ref": "#/definitions/spotify.EpisodePage"
+                },
                 "explicit": {
                     "description": "Whether or not the track has explicit lyrics.\ntrue =\u003e yes, it does; false =\u003e no, it does not.",
                     "type": "boolean"
@@ -371,7 +2807,11 @@ const docTemplate = `{
                 },
                 "linked_from": {
                     "description": "LinkedFrom points to the linked track. It's reported when the \"market\" parameter is passed to the tracks listing\nAPI.",
-                    "$ref": "#/definitions/spotify.LinkedFromInfo"
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/spotify.LinkedFromInfo"
+                        }
+                    ]
                 },
                 "name": {
                     "type": "string"
@@ -389,171 +2829,227 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "type": {
-                    "description": "Type of the track",
-                    "type": "string"
+                    "$ref": "#/definitions/spoty.ItemType"
                 },
                 "uri": {
                     "type": "string"
                 }
             }
         },
-        "spotify.Image": {
+        "spoty.Cursors": {
             "type": "object",
             "properties": {
-                "height": {
-                    "description": "The image height, in pixels.",
-                    "type": "integer"
-                },
-                "url": {
-                    "description": "The source URL of the image.",
+                "after": {
                     "type": "string"
                 },
-                "width": {
-                    "description": "The image width, in pixels.",
-                    "type": "integer"
+                "before": {
+                    "type": "string"
                 }
             }
         },
-        "spotify.LinkedFromInfo": {
+        "spoty.Device": {
             "type": "object",
             "properties": {
-                "external_urls": {
-                    "description": "ExternalURLs are the known external APIs for this track or album",
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
-                },
-                "href": {
-                    "description": "Href is a link to the Web API endpoint providing full details",
+                "id": {
                     "type": "string"
                 },
-                "id": {
-                    "description": "ID of the linked track",
+                "is_active": {
+                    "type": "boolean"
+                },
+                "is_restricted": {
+                    "type": "boolean"
+                },
+                "name": {
                     "type": "string"
                 },
                 "type": {
-                    "description": "Type of the link: album of the track",
                     "type": "string"
                 },
-                "uri": {
-                    "description": "URI is the Spotify URI of the track/album",
-                    "type": "string"
+                "volume_percent": {
+                    "type": "integer"
                 }
             }
         },
-        "spotify.SimpleAlbum": {
+        "spoty.Image": {
             "type": "object",
             "properties": {
-                "album_group": {
-                    "description": "The field is present when getting an artist’s\nalbums. Possible values are “album”, “single”,\n“compilation”, “appears_on”. Compare to album_type\nthis field represents relationship between the artist\nand the album.",
+                "blurhash": {
                     "type": "string"
                 },
-                "album_type": {
-                    "description": "The type of the album: one of \"album\",\n\"single\", or \"compilation\".",
+                "contrast": {
+                    "$ref": "#/definitions/palette.Contrast"
+                },
+                "error": {
                     "type": "string"
                 },
-                "artists": {
-                    "description": "A slice of SimpleArtists",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/spotify.SimpleArtist"
-                    }
+                "height": {
+                    "type": "integer"
                 },
-                "available_markets": {
-                    "description": "The markets in which the album is available,\nidentified using ISO 3166-1 alpha-2 country\ncodes.  Note that al album is considered\navailable in a market when at least 1 of its\ntracks is available in that market.",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "hex": {
+                    "type": "string"
                 },
-                "external_urls": {
-                    "description": "Known external URLs for this album.",
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                "hsl": {
+                    "$ref": "#/definitions/colors.HSL"
                 },
-                "href": {
-                    "description": "A link to the Web API enpoint providing full\ndetails of the album.",
+                "hsv": {
+                    "$ref": "#/definitions/colors.HSV"
+                },
+                "oklab": {
+                    "$ref": "#/definitions/colors.OKLab"
+                },
+                "oklch": {
+                    "$ref": "#/definitions/colors.OKLCH"
+                },
+                "palette": {
+                    "$ref": "#/definitions/palette.Palette"
+                },
+                "rgba": {
+                    "$ref": "#/definitions/color.RGBA"
+                },
+                "thumbhash": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "spoty.ImagesResult": {
+            "type": "object",
+            "properties": {
+                "error": {
                     "type": "string"
                 },
                 "id": {
-                    "description": "The SpotifyID for the album.",
                     "type": "string"
                 },
                 "images": {
-                    "description": "The cover art for the album in various sizes,\nwidest first.",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/spotify.Image"
+                        "$ref": "#/definitions/spoty.Image"
                     }
                 },
-                "name": {
-                    "description": "The name of the album.",
+                "type": {
+                    "$ref": "#/definitions/spoty.ResourceType"
+                }
+            }
+        },
+        "spoty.ItemType": {
+            "type": "string",
+            "enum": [
+                "track",
+                "episode"
+            ],
+            "x-enum-varnames": [
+                "ItemTypeTrack",
+                "ItemTypeEpisode"
+            ]
+        },
+        "spoty.PlaybackContext": {
+            "type": "object",
+            "properties": {
+                "external_url": {
                     "type": "string"
                 },
-                "release_date": {
-                    "description": "The date the album was first released.  For example, \"1981-12-15\".\nDepending on the ReleaseDatePrecision, it might be shown as\n\"1981\" or \"1981-12\". You can use ReleaseDateTime to convert this\nto a time.Time value.",
+                "href": {
                     "type": "string"
                 },
-                "release_date_precision": {
-                    "description": "The precision with which ReleaseDate value is known: \"year\", \"month\", or \"day\"",
+                "type": {
                     "type": "string"
                 },
                 "uri": {
-                    "description": "The SpotifyURI for the album.",
                     "type": "string"
                 }
             }
         },
-        "spotify.SimpleArtist": {
+        "spoty.PlaybackState": {
             "type": "object",
             "properties": {
-                "external_urls": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
+                "context": {
+                    "$ref": "#/definitions/spoty.PlaybackContext"
+                },
+                "device": {
+                    "$ref": "#/definitions/spoty.Device"
+                },
+                "episode": {
+                    "$ref": "#/definitions/spotify.EpisodePage"
+                },
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/spoty.Image"
                     }
                 },
-                "href": {
-                    "description": "A link to the Web API enpoint providing full details of the artist.",
-                    "type": "string"
+                "is_playing": {
+                    "type": "boolean"
                 },
-                "id": {
-                    "type": "string"
+                "progress_ms": {
+                    "type": "integer"
                 },
-                "name": {
+                "repeat_state": {
                     "type": "string"
                 },
-                "uri": {
-                    "description": "The Spotify URI for the artist.",
-                    "type": "string"
+                "shuffle_state": {
+                    "type": "boolean"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "track": {
+                    "$ref": "#/definitions/spotify.FullTrack"
+                },
+                "type": {
+                    "$ref": "#/definitions/spoty.ItemType"
                 }
             }
         },
-        "spoty.Image": {
+        "spoty.RecentItem": {
             "type": "object",
             "properties": {
-                "error": {
-                    "type": "string"
+                "context": {
+                    "$ref": "#/definitions/spoty.PlaybackContext"
                 },
-                "height": {
-                    "type": "integer"
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/spoty.Image"
+                    }
                 },
-                "hex": {
+                "played_at": {
                     "type": "string"
                 },
-                "rgba": {
-                    "$ref": "#/definitions/color.RGBA"
-                },
-                "url": {
-                    "type": "string"
+                "track": {
+                    "$ref": "#/definitions/spotify.FullTrack"
+                }
+            }
+        },
+        "spoty.RecentlyPlayed": {
+            "type": "object",
+            "properties": {
+                "cursors": {
+                    "$ref": "#/definitions/spoty.Cursors"
                 },
-                "width": {
-                    "type": "integer"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/spoty.RecentItem"
+                    }
                 }
             }
+        },
+        "spoty.ResourceType": {
+            "type": "string",
+            "enum": [
+                "track",
+                "album"
+            ],
+            "x-enum-varnames": [
+                "ResourceTypeTrack",
+                "ResourceTypeAlbum"
+            ]
         }
     }
 }`
@@ -568,6 +3064,8 @@ var SwaggerInfo = &swag.Spec{
 	Description:      "Access information about current playing track on spotify through REST endpoints.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
+	LeftDelim:        "{{",
+	RightDelim:       "}}",
 }
 
 func init() {
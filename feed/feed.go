@@ -0,0 +1,248 @@
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	_atomNS  = "http://www.w3.org/2005/Atom"
+	_spotyNS = "https://github.com/mgjules/spoty"
+)
+
+// Feed represents a syndication feed rendered as Atom or RSS.
+type Feed struct {
+	// ID is a permanent and unique identifier of the feed (e.g. a URN).
+	ID       string
+	Title    string
+	Subtitle string
+	// Link is the URL of the feed itself.
+	Link    string
+	Updated time.Time
+	Entries []Entry
+}
+
+// Entry represents an entry of a feed.
+type Entry struct {
+	// ID is a permanent and unique identifier of the entry (e.g. a URN).
+	ID        string
+	Title     string
+	Link      string
+	Author    string
+	Summary   string
+	Published time.Time
+	Updated   time.Time
+	// Image is an optional image enclosure.
+	Image *Enclosure
+	// Color is an optional hex color associated to the entry.
+	Color string
+}
+
+// Enclosure represents a media resource attached to an entry.
+type Enclosure struct {
+	URL  string
+	Type string
+}
+
+// WriteAtom writes the feed as an Atom 1.0 document.
+// See https://www.rfc-editor.org/rfc/rfc4287.
+func (f *Feed) WriteAtom(w io.Writer) error {
+	doc := atomFeed{
+		NS:       _atomNS,
+		SpotyNS:  _spotyNS,
+		ID:       f.ID,
+		Title:    f.Title,
+		Subtitle: f.Subtitle,
+		Updated:  atomTime(f.Updated),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: f.Link},
+		},
+	}
+
+	for i := range f.Entries {
+		e := &f.Entries[i]
+
+		entry := atomEntry{
+			ID:        e.ID,
+			Title:     e.Title,
+			Published: atomTime(e.Published),
+			Updated:   atomTime(e.Updated),
+			Color:     e.Color,
+		}
+
+		if e.Author != "" {
+			entry.Author = &atomPerson{Name: e.Author}
+		}
+
+		if e.Summary != "" {
+			entry.Summary = &atomText{Type: "text", Body: e.Summary}
+		}
+
+		if e.Link != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: "alternate", Type: "text/html", Href: e.Link})
+		} else {
+			// An entry without an alternate link must have a content.
+			body := e.Summary
+			if body == "" {
+				body = e.Title
+			}
+
+			entry.Content = &atomText{Type: "text", Body: body}
+		}
+
+		if e.Image != nil {
+			entry.Links = append(entry.Links, atomLink{Rel: "enclosure", Type: e.Image.Type, Href: e.Image.URL})
+		}
+
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return write(w, doc)
+}
+
+// WriteRSS writes the feed as an RSS 2.0 document.
+// See https://www.rssboard.org/rss-specification.
+func (f *Feed) WriteRSS(w io.Writer) error {
+	doc := rssFeed{
+		Version: "2.0",
+		AtomNS:  _atomNS,
+		SpotyNS: _spotyNS,
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.Link,
+			Description:   f.Subtitle,
+			LastBuildDate: rssTime(f.Updated),
+			AtomLink: atomLink{
+				Rel:  "self",
+				Type: "application/rss+xml",
+				Href: f.Link,
+			},
+		},
+	}
+
+	for i := range f.Entries {
+		e := &f.Entries[i]
+
+		item := rssItem{
+			Title:       e.Title,
+			Link:        e.Link,
+			Description: e.Summary,
+			Artists:     e.Author,
+			GUID:        rssGUID{IsPermaLink: "false", Value: e.ID},
+			PubDate:     rssTime(e.Published),
+			Color:       e.Color,
+		}
+
+		if e.Image != nil {
+			// The length is unknown: 0 is the recommended value in that case.
+			item.Enclosure = &rssEnclosure{URL: e.Image.URL, Type: e.Image.Type, Length: "0"}
+		}
+
+		doc.Channel.Items = append(doc.Channel.Items, item)
+	}
+
+	return write(w, doc)
+}
+
+func write(w io.Writer, doc any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("encode feed: %w", err)
+	}
+
+	return enc.Flush()
+}
+
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func rssTime(t time.Time) string {
+	return t.UTC().Format(time.RFC1123Z)
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	NS       string      `xml:"xmlns,attr"`
+	SpotyNS  string      `xml:"xmlns:spoty,attr"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Links     []atomLink  `xml:"link"`
+	Author    *atomPerson `xml:"author,omitempty"`
+	Summary   *atomText   `xml:"summary,omitempty"`
+	Content   *atomText   `xml:"content,omitempty"`
+	Published string      `xml:"published"`
+	Updated   string      `xml:"updated"`
+	Color     string      `xml:"spoty:color,omitempty"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	SpotyNS string     `xml:"xmlns:spoty,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link,omitempty"`
+	Description string        `xml:"description,omitempty"`
+	Artists     string        `xml:"spoty:artists,omitempty"`
+	Enclosure   *rssEnclosure `xml:"enclosure,omitempty"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Color       string        `xml:"spoty:color,omitempty"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
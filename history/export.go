@@ -0,0 +1,431 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mgjules/spoty/json"
+	bolt "go.etcd.io/bbolt"
+)
+
+// _eachBatchSize is the number of plays read per transaction by Each.
+// Keeping transactions short avoids blocking the recorder on slow consumers.
+const _eachBatchSize = 500
+
+// Format represents an export format.
+type Format string
+
+// Supported export formats.
+const (
+	FormatCSV          Format = "csv"
+	FormatNDJSON       Format = "ndjson"
+	FormatJSON         Format = "json"
+	FormatListenBrainz Format = "listenbrainz"
+)
+
+// ErrUnsupportedFormat is returned when parsing an unknown export format.
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// ParseFormat parses an export format.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatCSV, FormatNDJSON, FormatJSON, FormatListenBrainz:
+		return f, nil
+	default:
+		return "", fmt.Errorf("%w %q", ErrUnsupportedFormat, s)
+	}
+}
+
+// ContentType returns the MIME type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// Extension returns the file extension of the format.
+func (f Format) Extension() string {
+	switch f {
+	case FormatCSV:
+		return "csv"
+	case FormatNDJSON:
+		return "ndjson"
+	default:
+		return "json"
+	}
+}
+
+// ExportQuery represents the filters used to export plays.
+type ExportQuery struct {
+	// From filters out plays started before it (inclusive). Ignored if zero.
+	From time.Time
+	// To filters out plays started at or after it (exclusive). Ignored if zero.
+	To time.Time
+	// Format is the export format.
+	Format Format
+}
+
+// Each calls fn for every play started within [from, to), oldest first.
+// A zero from or to is ignored. Iteration stops at the first error returned by fn.
+func (h *History) Each(ctx context.Context, from, to time.Time, fn func(play *Play) error) error {
+	ctx, span := h.tracer.Start(ctx, "Each")
+	defer span.End()
+
+	next := timeKey(from)
+	if from.IsZero() {
+		next = nil
+	}
+
+	var upper []byte
+	if !to.IsZero() {
+		upper = timeKey(to)
+	}
+
+	batch := make([]Play, 0, _eachBatchSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		batch = batch[:0]
+
+		if err := h.store.View(func(tx *bolt.Tx) error {
+			c := tx.Bucket(_playsBucket).Cursor()
+
+			k, v := c.Seek(next)
+			if next == nil {
+				k, v = c.First()
+			}
+
+			for ; k != nil && len(batch) < _eachBatchSize; k, v = c.Next() {
+				if upper != nil && bytes.Compare(k, upper) >= 0 {
+					break
+				}
+
+				var play Play
+				if err := json.Unmarshal(v, &play); err != nil {
+					return fmt.Errorf("unmarshal play %q: %w", k, err)
+				}
+
+				batch = append(batch, play)
+			}
+
+			next = nil
+			if k != nil && len(batch) == _eachBatchSize {
+				next = append([]byte(nil), k...)
+			}
+
+			return nil
+		}); err != nil {
+			h.logger.ErrorwContext(ctx, "failed to iterate over plays", "error", err.Error())
+
+			return err
+		}
+
+		for i := range batch {
+			if err := fn(&batch[i]); err != nil {
+				return err
+			}
+		}
+
+		if next == nil {
+			return nil
+		}
+	}
+}
+
+// Export streams the plays matching the query to w, oldest first, in the requested format.
+// It returns the number of exported plays.
+func (h *History) Export(ctx context.Context, w io.Writer, q ExportQuery) (int, error) {
+	ctx, span := h.tracer.Start(ctx, "Export")
+	defer span.End()
+
+	enc, err := newEncoder(q.Format, w)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := enc.begin(); err != nil {
+		return 0, fmt.Errorf("begin export: %w", err)
+	}
+
+	var n int
+	if err := h.Each(ctx, q.From, q.To, func(play *Play) error {
+		written, err := enc.encode(play)
+		if err != nil {
+			return fmt.Errorf("encode play %q: %w", play.ID, err)
+		}
+
+		if written {
+			n++
+		}
+
+		return nil
+	}); err != nil {
+		return n, err
+	}
+
+	if err := enc.end(); err != nil {
+		return n, fmt.Errorf("end export: %w", err)
+	}
+
+	return n, nil
+}
+
+// encoder writes plays in a given format.
+type encoder interface {
+	begin() error
+	// encode writes a play. It returns false if the play is not supported by the format.
+	encode(play *Play) (bool, error)
+	end() error
+}
+
+func newEncoder(format Format, w io.Writer) (encoder, error) {
+	switch format {
+	case FormatCSV:
+		return &csvEncoder{w: csv.NewWriter(w)}, nil
+	case FormatNDJSON:
+		return &ndjsonEncoder{enc: json.NewEncoder(w)}, nil
+	case FormatJSON:
+		return &jsonEncoder{w: w}, nil
+	case FormatListenBrainz:
+		return &listenBrainzEncoder{jsonEncoder{w: w}}, nil
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedFormat, format)
+	}
+}
+
+var _csvHeader = []string{
+	"id",
+	"started_at",
+	"ended_at",
+	"item_type",
+	"item_id",
+	"name",
+	"artists",
+	"album",
+	"genres",
+	"duration_ms",
+	"listened_ms",
+	"outcome",
+	"repeat",
+	"device",
+	"context_uri",
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvEncoder) begin() error {
+	return e.w.Write(_csvHeader)
+}
+
+func (e *csvEncoder) encode(play *Play) (bool, error) {
+	artists := make([]string, 0, len(play.Artists))
+	for _, artist := range play.Artists {
+		artists = append(artists, artist.Name)
+	}
+
+	var device, contextURI string
+	if play.Device != nil {
+		device = play.Device.Name
+	}
+
+	if play.Context != nil {
+		contextURI = play.Context.URI
+	}
+
+	return true, e.w.Write([]string{
+		play.ID,
+		play.StartedAt.UTC().Format(time.RFC3339),
+		play.EndedAt.UTC().Format(time.RFC3339),
+		play.ItemType,
+		play.ItemID,
+		play.Name,
+		strings.Join(artists, "; "),
+		play.Album.Name,
+		strings.Join(play.Genres, "; "),
+		strconv.FormatInt(play.DurationMs, 10),
+		strconv.FormatInt(play.ListenedMs, 10),
+		string(play.Outcome),
+		strconv.FormatBool(play.Repeat),
+		device,
+		contextURI,
+	})
+}
+
+func (e *csvEncoder) end() error {
+	e.w.Flush()
+
+	return e.w.Error()
+}
+
+type ndjsonEncoder struct {
+	enc interface{ Encode(v any) error }
+}
+
+func (*ndjsonEncoder) begin() error {
+	return nil
+}
+
+func (e *ndjsonEncoder) encode(play *Play) (bool, error) {
+	return true, e.enc.Encode(play)
+}
+
+func (*ndjsonEncoder) end() error {
+	return nil
+}
+
+// jsonEncoder writes the plays as a single JSON array, one element at a time.
+type jsonEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonEncoder) begin() error {
+	_, err := io.WriteString(e.w, "[")
+
+	return err
+}
+
+func (e *jsonEncoder) encode(play *Play) (bool, error) {
+	return true, e.element(play)
+}
+
+func (e *jsonEncoder) element(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if e.count > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+
+	e.count++
+
+	if _, err := io.WriteString(e.w, "\n"); err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(data)
+
+	return err
+}
+
+func (e *jsonEncoder) end() error {
+	_, err := io.WriteString(e.w, "\n]\n")
+
+	return err
+}
+
+// listenBrainzEncoder writes the plays of tracks as a ListenBrainz "import" submission.
+// See https://listenbrainz.readthedocs.io/en/latest/users/json.html.
+type listenBrainzEncoder struct {
+	jsonEncoder
+}
+
+type listenBrainzListen struct {
+	ListenedAt    int64                     `json:"listened_at"`
+	TrackMetadata listenBrainzTrackMetadata `json:"track_metadata"`
+}
+
+type listenBrainzTrackMetadata struct {
+	ArtistName     string                     `json:"artist_name"`
+	TrackName      string                     `json:"track_name"`
+	ReleaseName    string                     `json:"release_name,omitempty"`
+	AdditionalInfo listenBrainzAdditionalInfo `json:"additional_info"`
+}
+
+type listenBrainzAdditionalInfo struct {
+	DurationMs       int64    `json:"duration_ms,omitempty"`
+	MediaPlayer      string   `json:"media_player"`
+	SubmissionClient string   `json:"submission_client"`
+	MusicService     string   `json:"music_service"`
+	OriginURL        string   `json:"origin_url,omitempty"`
+	SpotifyID        string   `json:"spotify_id,omitempty"`
+	SpotifyAlbumID   string   `json:"spotify_album_id,omitempty"`
+	SpotifyArtistIDs []string `json:"spotify_artist_ids,omitempty"`
+	Tags             []string `json:"tags,omitempty"`
+}
+
+func (e *listenBrainzEncoder) begin() error {
+	_, err := io.WriteString(e.w, `{"listen_type":"import","payload":[`)
+
+	return err
+}
+
+func (e *listenBrainzEncoder) encode(play *Play) (bool, error) {
+	// ListenBrainz only accepts music listens.
+	if play.ItemType != "track" {
+		return false, nil
+	}
+
+	artists := make([]string, 0, len(play.Artists))
+	artistIDs := make([]string, 0, len(play.Artists))
+	for _, artist := range play.Artists {
+		artists = append(artists, artist.Name)
+
+		if artist.ID != "" {
+			artistIDs = append(artistIDs, spotifyURL("artist", artist.ID))
+		}
+	}
+
+	if len(artists) == 0 {
+		return false, nil
+	}
+
+	listen := listenBrainzListen{
+		ListenedAt: play.StartedAt.Unix(),
+		TrackMetadata: listenBrainzTrackMetadata{
+			ArtistName:  strings.Join(artists, ", "),
+			TrackName:   play.Name,
+			ReleaseName: play.Album.Name,
+			AdditionalInfo: listenBrainzAdditionalInfo{
+				DurationMs:       play.DurationMs,
+				MediaPlayer:      "Spotify",
+				SubmissionClient: "spoty",
+				MusicService:     "spotify.com",
+				SpotifyArtistIDs: artistIDs,
+				Tags:             play.Genres,
+			},
+		},
+	}
+
+	// Plays imported without an ID have no spotify URL.
+	if !IsDerivedID(play.ItemID) {
+		listen.TrackMetadata.AdditionalInfo.OriginURL = spotifyURL("track", play.ItemID)
+		listen.TrackMetadata.AdditionalInfo.SpotifyID = spotifyURL("track", play.ItemID)
+	}
+
+	if play.Album.ID != "" {
+		listen.TrackMetadata.AdditionalInfo.SpotifyAlbumID = spotifyURL("album", play.Album.ID)
+	}
+
+	return true, e.element(listen)
+}
+
+func (e *listenBrainzEncoder) end() error {
+	_, err := io.WriteString(e.w, "\n]}\n")
+
+	return err
+}
+
+func spotifyURL(kind, id string) string {
+	return "https://open.spotify.com/" + kind + "/" + id
+}
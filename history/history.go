@@ -0,0 +1,270 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mgjules/spoty/json"
+	"github.com/mgjules/spoty/logger"
+	"github.com/mgjules/spoty/store"
+	"github.com/mgjules/spoty/tracer"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/fx"
+)
+
+const (
+	_defaultLimit = 50
+	_maxLimit     = 500
+)
+
+var _playsBucket = []byte("plays")
+
+// Module exported for initialising a new History.
+var Module = fx.Options(
+	fx.Provide(New),
+)
+
+// Artist represents an artist of a track or the publisher of an episode.
+type Artist struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+// Album represents the album of a track or the show of an episode.
+type Album struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+// Device represents the device on which a play happened.
+type Device struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Context represents the context (playlist, album, artist, ...) of a play.
+type Context struct {
+	Type string `json:"type"`
+	URI  string `json:"uri"`
+}
+
+// Outcome represents how a play ended.
+type Outcome string
+
+// Possible outcomes of a play.
+const (
+	OutcomeCompleted    Outcome = "completed"
+	OutcomeSkippedEarly Outcome = "skipped_early"
+	OutcomeSkippedLate  Outcome = "skipped_late"
+)
+
+// Play represents a single recorded playback of a track or an episode.
+type Play struct {
+	ID         string    `json:"id"`
+	ItemType   string    `json:"item_type"`
+	ItemID     string    `json:"item_id"`
+	Name       string    `json:"name"`
+	Artists    []Artist  `json:"artists"`
+	Album      Album     `json:"album"`
+	Genres     []string  `json:"genres,omitempty"`
+	ImageURL   string    `json:"image_url,omitempty"`
+	Color      string    `json:"color,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	StartedAt  time.Time `json:"started_at"`
+	EndedAt    time.Time `json:"ended_at"`
+	ListenedMs int64     `json:"listened_ms"`
+	Outcome    Outcome   `json:"outcome,omitempty"`
+	// Repeat tells whether the play replays the item of the previous one.
+	Repeat  bool     `json:"repeat,omitempty"`
+	Device  *Device  `json:"device,omitempty"`
+	Context *Context `json:"context,omitempty"`
+}
+
+// Query represents the filters used to list plays.
+type Query struct {
+	// From filters out plays started before it (inclusive). Ignored if zero.
+	From time.Time
+	// To filters out plays started at or after it (exclusive). Ignored if zero.
+	To time.Time
+	// Limit is the maximum number of plays to return.
+	Limit int
+	// Cursor is the ID of the last play of the previous page.
+	Cursor string
+}
+
+// Page represents a page of plays, most recent first.
+type Page struct {
+	Plays []Play `json:"plays"`
+	Next  string `json:"next,omitempty"`
+}
+
+// History represents the local listening history.
+type History struct {
+	store  *store.Store
+	logger *logger.Logger
+	tracer *tracer.Tracer
+}
+
+// New creates a new History.
+func New(store *store.Store, logger *logger.Logger, tracer *tracer.Tracer) (*History, error) {
+	if err := store.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{_playsBucket, _rollupsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create history buckets: %w", err)
+	}
+
+	return &History{
+		store:  store,
+		logger: logger,
+		tracer: tracer,
+	}, nil
+}
+
+// Record stores a play in the history and updates the rollup of its day.
+func (h *History) Record(ctx context.Context, play *Play) error {
+	ctx, span := h.tracer.Start(ctx, "Record")
+	defer span.End()
+
+	if play == nil || play.ItemID == "" || play.StartedAt.IsZero() {
+		return errors.New("invalid play")
+	}
+
+	play.ID = playKey(play.StartedAt, play.ItemID)
+
+	if err := h.store.Update(func(tx *bolt.Tx) error {
+		return put(tx, play)
+	}); err != nil {
+		h.logger.ErrorwContext(ctx, "failed to record play", "error", err.Error(), "play", play)
+
+		return err
+	}
+
+	h.logger.Ctx(ctx).Debugw("recorded play", "play", play)
+
+	return nil
+}
+
+// List returns a page of plays matching the query, most recent first.
+func (h *History) List(ctx context.Context, q Query) (*Page, error) {
+	ctx, span := h.tracer.Start(ctx, "List")
+	defer span.End()
+
+	if q.Limit <= 0 {
+		q.Limit = _defaultLimit
+	} else if q.Limit > _maxLimit {
+		q.Limit = _maxLimit
+	}
+
+	var lower []byte
+	if !q.From.IsZero() {
+		lower = timeKey(q.From)
+	}
+
+	page := Page{
+		Plays: make([]Play, 0, q.Limit),
+	}
+
+	if err := h.store.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(_playsBucket).Cursor()
+
+		var k, v []byte
+		switch {
+		case q.Cursor != "":
+			k, v = seekBefore(c, []byte(q.Cursor))
+		case !q.To.IsZero():
+			k, v = seekBefore(c, timeKey(q.To))
+		default:
+			k, v = c.Last()
+		}
+
+		for ; k != nil && bytes.Compare(k, lower) >= 0; k, v = c.Prev() {
+			if len(page.Plays) == q.Limit {
+				page.Next = page.Plays[len(page.Plays)-1].ID
+
+				break
+			}
+
+			var play Play
+			if err := json.Unmarshal(v, &play); err != nil {
+				return fmt.Errorf("unmarshal play %q: %w", k, err)
+			}
+
+			page.Plays = append(page.Plays, play)
+		}
+
+		return nil
+	}); err != nil {
+		h.logger.ErrorwContext(ctx, "failed to list plays", "error", err.Error())
+
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+// put stores a play and updates the rollups within a transaction.
+// An existing play with the same key is replaced.
+func put(tx *bolt.Tx, play *Play) error {
+	b := tx.Bucket(_playsBucket)
+	key := []byte(play.ID)
+
+	if data := b.Get(key); data != nil {
+		var existing Play
+		if err := json.Unmarshal(data, &existing); err != nil {
+			return fmt.Errorf("unmarshal play %q: %w", key, err)
+		}
+
+		if err := updateRollup(tx, &existing, -1); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.Marshal(play)
+	if err != nil {
+		return fmt.Errorf("marshal play: %w", err)
+	}
+
+	if err := b.Put(key, data); err != nil {
+		return err
+	}
+
+	return updateRollup(tx, play, 1)
+}
+
+// seekBefore moves the cursor to the last key strictly lower than the given key.
+func seekBefore(c *bolt.Cursor, key []byte) ([]byte, []byte) {
+	k, _ := c.Seek(key)
+	if k == nil {
+		return c.Last()
+	}
+
+	return c.Prev()
+}
+
+// timeKey returns the key prefix for a given time.
+// Keys are zero-padded so that their lexicographic order matches the chronological order.
+func timeKey(t time.Time) []byte {
+	return []byte(fmt.Sprintf("%020d", t.UnixNano()))
+}
+
+// IsDerivedID tells whether an item ID was derived from the names of the item, as done for
+// the plays imported without one, rather than given by spotify.
+func IsDerivedID(id string) bool {
+	return strings.Contains(id, ":")
+}
+
+// playKey returns the key of a play.
+func playKey(startedAt time.Time, itemID string) string {
+	return fmt.Sprintf("%020d:%s", startedAt.UnixNano(), itemID)
+}
@@ -0,0 +1,113 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mgjules/spoty/json"
+	bolt "go.etcd.io/bbolt"
+)
+
+// _duplicateWindow is how far apart the start of two plays of the same item can be for them to be considered duplicates.
+// Some sources only have a minute precision and the start of a play is estimated differently by each source.
+const _duplicateWindow = 2 * time.Minute
+
+// ImportResult represents the outcome of an import.
+type ImportResult struct {
+	Imported   int `json:"imported"`
+	Duplicates int `json:"duplicates"`
+}
+
+// Add adds the counts of another result.
+func (r *ImportResult) Add(o ImportResult) {
+	r.Imported += o.Imported
+	r.Duplicates += o.Duplicates
+}
+
+// Import stores plays coming from another source (e.g. a spotify data export) in a single transaction.
+// A play is skipped if a play of the same item, already in the history or imported before it,
+// started around the same time.
+func (h *History) Import(ctx context.Context, plays []*Play) (ImportResult, error) {
+	ctx, span := h.tracer.Start(ctx, "Import")
+	defer span.End()
+
+	var result ImportResult
+
+	if err := h.store.Update(func(tx *bolt.Tx) error {
+		for _, play := range plays {
+			if play == nil || play.ItemID == "" || play.StartedAt.IsZero() {
+				return errors.New("invalid play")
+			}
+
+			duplicate, err := hasDuplicate(tx, play)
+			if err != nil {
+				return err
+			}
+
+			if duplicate {
+				result.Duplicates++
+
+				continue
+			}
+
+			play.ID = playKey(play.StartedAt, play.ItemID)
+
+			if err := put(tx, play); err != nil {
+				return err
+			}
+
+			result.Imported++
+		}
+
+		return nil
+	}); err != nil {
+		h.logger.ErrorwContext(ctx, "failed to import plays", "error", err.Error())
+
+		return ImportResult{}, err
+	}
+
+	h.logger.Ctx(ctx).Debugw("imported plays", "imported", result.Imported, "duplicates", result.Duplicates)
+
+	return result, nil
+}
+
+// hasDuplicate tells whether a play of the same item started within the duplicate window of the given play.
+func hasDuplicate(tx *bolt.Tx, play *Play) (bool, error) {
+	c := tx.Bucket(_playsBucket).Cursor()
+	upper := timeKey(play.StartedAt.Add(_duplicateWindow + time.Nanosecond))
+
+	for k, v := c.Seek(timeKey(play.StartedAt.Add(-_duplicateWindow))); k != nil && bytes.Compare(k, upper) < 0; k, v = c.Next() {
+		var existing Play
+		if err := json.Unmarshal(v, &existing); err != nil {
+			return false, fmt.Errorf("unmarshal play %q: %w", k, err)
+		}
+
+		if samePlayed(&existing, play) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// samePlayed tells whether two plays are of the same item.
+// The name and main artist are compared as some sources do not provide the item ID.
+func samePlayed(a, b *Play) bool {
+	if a.ItemID == b.ItemID {
+		return true
+	}
+
+	if !strings.EqualFold(a.Name, b.Name) {
+		return false
+	}
+
+	if len(a.Artists) == 0 || len(b.Artists) == 0 {
+		return len(a.Artists) == len(b.Artists)
+	}
+
+	return strings.EqualFold(a.Artists[0].Name, b.Artists[0].Name)
+}
@@ -0,0 +1,279 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mgjules/spoty/json"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// DefaultSessionGap is the default break after which a new session starts.
+	DefaultSessionGap = 20 * time.Minute
+
+	_defaultSessionLimit = 20
+	_maxSessionLimit     = 100
+	_paletteSize         = 5
+)
+
+// Session represents a period of continuous listening.
+type Session struct {
+	ID         string    `json:"id"`
+	StartedAt  time.Time `json:"started_at"`
+	EndedAt    time.Time `json:"ended_at"`
+	DurationMs int64     `json:"duration_ms"`
+	ListenedMs int64     `json:"listened_ms"`
+	Tracks     int       `json:"tracks"`
+	Device     *Device   `json:"device,omitempty"`
+	Palette    []string  `json:"palette,omitempty"`
+}
+
+// NewSession returns an empty session started at the given time.
+func NewSession(startedAt time.Time) *Session {
+	return &Session{
+		ID:        string(timeKey(startedAt)),
+		StartedAt: startedAt,
+		EndedAt:   startedAt,
+	}
+}
+
+// SessionBuilder groups plays into a single session.
+// Plays can be added in any chronological order.
+type SessionBuilder struct {
+	gap     time.Duration
+	session *Session
+
+	devices map[string]*weighted
+	colors  map[string]*weighted
+}
+
+// weighted represents a value weighted by listened duration.
+type weighted struct {
+	device     *Device
+	listenedMs int64
+}
+
+// NewSessionBuilder creates a new SessionBuilder.
+// A gap of zero or less uses the default session gap.
+func NewSessionBuilder(gap time.Duration) *SessionBuilder {
+	if gap <= 0 {
+		gap = DefaultSessionGap
+	}
+
+	return &SessionBuilder{
+		gap:     gap,
+		devices: make(map[string]*weighted),
+		colors:  make(map[string]*weighted),
+	}
+}
+
+// Fits tells whether a play belongs to the session being built.
+// Any play fits an empty session.
+func (b *SessionBuilder) Fits(play *Play) bool {
+	if b.session == nil {
+		return true
+	}
+
+	return play.StartedAt.Sub(b.session.EndedAt) <= b.gap &&
+		b.session.StartedAt.Sub(play.EndedAt) <= b.gap
+}
+
+// Add adds a play to the session. It returns false, without adding it, if the play does not fit.
+func (b *SessionBuilder) Add(play *Play) bool {
+	if !b.Fits(play) {
+		return false
+	}
+
+	if b.session == nil {
+		b.session = NewSession(play.StartedAt)
+	}
+
+	s := b.session
+	if play.StartedAt.Before(s.StartedAt) {
+		s.ID = string(timeKey(play.StartedAt))
+		s.StartedAt = play.StartedAt
+	}
+
+	if play.EndedAt.After(s.EndedAt) {
+		s.EndedAt = play.EndedAt
+	}
+
+	s.DurationMs = s.EndedAt.Sub(s.StartedAt).Milliseconds()
+	s.ListenedMs += play.ListenedMs
+	s.Tracks++
+
+	if play.Device != nil {
+		key := keyOrName(play.Device.ID, play.Device.Name)
+
+		w, ok := b.devices[key]
+		if !ok {
+			w = &weighted{device: play.Device}
+			b.devices[key] = w
+		}
+
+		w.listenedMs += play.ListenedMs
+	}
+
+	if play.Color != "" {
+		w, ok := b.colors[play.Color]
+		if !ok {
+			w = &weighted{}
+			b.colors[play.Color] = w
+		}
+
+		w.listenedMs += play.ListenedMs
+	}
+
+	return true
+}
+
+func keyOrName(key, name string) string {
+	if key != "" {
+		return key
+	}
+
+	return name
+}
+
+// Empty tells whether no play was added yet.
+func (b *SessionBuilder) Empty() bool {
+	return b.session == nil
+}
+
+// Session returns the session built so far or nil if it is empty.
+// The dominant device is the one listened to the longest and the palette
+// holds the cover colors of the session, most listened first.
+func (b *SessionBuilder) Session() *Session {
+	if b.session == nil {
+		return nil
+	}
+
+	session := *b.session
+
+	var dominant *weighted
+	for _, w := range b.devices {
+		if dominant == nil || w.listenedMs > dominant.listenedMs {
+			dominant = w
+		}
+	}
+
+	if dominant != nil {
+		session.Device = dominant.device
+	}
+
+	palette := make([]string, 0, len(b.colors))
+	for color := range b.colors {
+		palette = append(palette, color)
+	}
+
+	sort.Slice(palette, func(i, j int) bool {
+		li, lj := b.colors[palette[i]].listenedMs, b.colors[palette[j]].listenedMs
+		if li != lj {
+			return li > lj
+		}
+
+		return palette[i] < palette[j]
+	})
+
+	if len(palette) > _paletteSize {
+		palette = palette[:_paletteSize]
+	}
+
+	session.Palette = palette
+
+	return &session
+}
+
+// SessionQuery represents the filters used to list sessions.
+type SessionQuery struct {
+	// From filters out plays started before it (inclusive). Ignored if zero.
+	// The oldest session may be truncated by it.
+	From time.Time
+	// To filters out plays started at or after it (exclusive). Ignored if zero.
+	To time.Time
+	// Gap is the break after which a new session starts. Defaults to DefaultSessionGap.
+	Gap time.Duration
+	// Limit is the maximum number of sessions to return.
+	Limit int
+	// Cursor is the ID of the last session of the previous page.
+	Cursor string
+}
+
+// SessionPage represents a page of sessions, most recent first.
+type SessionPage struct {
+	Sessions []Session `json:"sessions"`
+	Next     string    `json:"next,omitempty"`
+}
+
+// Sessions returns a page of listening sessions built from the plays matching the query, most recent first.
+func (h *History) Sessions(ctx context.Context, q SessionQuery) (*SessionPage, error) {
+	ctx, span := h.tracer.Start(ctx, "Sessions")
+	defer span.End()
+
+	if q.Limit <= 0 {
+		q.Limit = _defaultSessionLimit
+	} else if q.Limit > _maxSessionLimit {
+		q.Limit = _maxSessionLimit
+	}
+
+	var lower []byte
+	if !q.From.IsZero() {
+		lower = timeKey(q.From)
+	}
+
+	page := SessionPage{
+		Sessions: make([]Session, 0, q.Limit),
+	}
+
+	if err := h.store.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(_playsBucket).Cursor()
+
+		var k, v []byte
+		switch {
+		case q.Cursor != "":
+			k, v = seekBefore(c, []byte(q.Cursor))
+		case !q.To.IsZero():
+			k, v = seekBefore(c, timeKey(q.To))
+		default:
+			k, v = c.Last()
+		}
+
+		builder := NewSessionBuilder(q.Gap)
+		for ; k != nil && bytes.Compare(k, lower) >= 0; k, v = c.Prev() {
+			var play Play
+			if err := json.Unmarshal(v, &play); err != nil {
+				return fmt.Errorf("unmarshal play %q: %w", k, err)
+			}
+
+			if builder.Add(&play) {
+				continue
+			}
+
+			page.Sessions = append(page.Sessions, *builder.Session())
+			if len(page.Sessions) == q.Limit {
+				page.Next = page.Sessions[len(page.Sessions)-1].ID
+
+				return nil
+			}
+
+			builder = NewSessionBuilder(q.Gap)
+			builder.Add(&play)
+		}
+
+		if !builder.Empty() {
+			page.Sessions = append(page.Sessions, *builder.Session())
+		}
+
+		return nil
+	}); err != nil {
+		h.logger.ErrorwContext(ctx, "failed to list sessions", "error", err.Error())
+
+		return nil, err
+	}
+
+	return &page, nil
+}
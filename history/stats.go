@@ -0,0 +1,445 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mgjules/spoty/json"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	_dayLayout         = "2006-01-02"
+	_defaultStatsLimit = 10
+	_maxStatsLimit     = 50
+	_hoursPerDay       = 24
+	_daysPerWeek       = 7
+)
+
+// _rollupsBucket holds the daily rollups, whose counters are keyed by name.
+var _rollupsBucket = []byte("rollups")
+
+// counter represents the aggregated plays of a single track, artist, album or genre.
+// Its ID is the spotify ID of the item, when any of its plays had one.
+type counter struct {
+	ID         string `json:"id,omitempty"`
+	Name       string `json:"name"`
+	Plays      int64  `json:"plays"`
+	ListenedMs int64  `json:"listened_ms"`
+}
+
+// rollup represents the pre-computed aggregates of the plays started on a single (UTC) day.
+type rollup struct {
+	Plays      int64               `json:"plays"`
+	ListenedMs int64               `json:"listened_ms"`
+	Hours      [_hoursPerDay]int64 `json:"hours"`
+	Tracks     map[string]*counter `json:"tracks"`
+	Artists    map[string]*counter `json:"artists"`
+	Albums     map[string]*counter `json:"albums"`
+	Genres     map[string]*counter `json:"genres"`
+}
+
+func newRollup() *rollup {
+	return &rollup{
+		Tracks:  make(map[string]*counter),
+		Artists: make(map[string]*counter),
+		Albums:  make(map[string]*counter),
+		Genres:  make(map[string]*counter),
+	}
+}
+
+// add adds (sign=1) or removes (sign=-1) the contribution of a play to the rollup.
+// Counters are keyed by name so that the imported plays, which lack most IDs, add up with the recorded ones.
+func (r *rollup) add(play *Play, sign int64) {
+	listened := sign * play.ListenedMs
+
+	r.Plays += sign
+	r.ListenedMs += listened
+	r.Hours[play.StartedAt.UTC().Hour()] += listened
+
+	var artist string
+	if len(play.Artists) > 0 {
+		artist = play.Artists[0].Name
+	}
+
+	var itemID string
+	if !IsDerivedID(play.ItemID) {
+		itemID = play.ItemID
+	}
+
+	count(r.Tracks, nameKey(artist, play.Name), itemID, play.Name, sign, listened)
+
+	for _, a := range play.Artists {
+		count(r.Artists, nameKey(a.Name), a.ID, a.Name, sign, listened)
+	}
+
+	if play.Album.Name != "" {
+		count(r.Albums, nameKey(artist, play.Album.Name), play.Album.ID, play.Album.Name, sign, listened)
+	}
+
+	for _, genre := range play.Genres {
+		count(r.Genres, genre, "", genre, sign, listened)
+	}
+}
+
+// merge adds the aggregates of another rollup.
+func (r *rollup) merge(o *rollup) {
+	r.Plays += o.Plays
+	r.ListenedMs += o.ListenedMs
+
+	for h := range o.Hours {
+		r.Hours[h] += o.Hours[h]
+	}
+
+	mergeCounters(r.Tracks, o.Tracks)
+	mergeCounters(r.Artists, o.Artists)
+	mergeCounters(r.Albums, o.Albums)
+	mergeCounters(r.Genres, o.Genres)
+}
+
+func count(counters map[string]*counter, key, id, name string, plays, listened int64) {
+	c, ok := counters[key]
+	if !ok {
+		c = &counter{Name: name}
+		counters[key] = c
+	}
+
+	// The name given along with an ID is the one from spotify.
+	if c.ID == "" && id != "" {
+		c.ID, c.Name = id, name
+	}
+
+	c.Plays += plays
+	c.ListenedMs += listened
+
+	if c.Plays <= 0 {
+		delete(counters, key)
+	}
+}
+
+func mergeCounters(dst, src map[string]*counter) {
+	for key, c := range src {
+		count(dst, key, c.ID, c.Name, c.Plays, c.ListenedMs)
+	}
+}
+
+// nameKey returns the key of a counter from the names identifying it, ignoring their case.
+func nameKey(names ...string) string {
+	return strings.ToLower(strings.Join(names, "\x00"))
+}
+
+// updateRollup updates the rollup of the day of the play within a transaction.
+func updateRollup(tx *bolt.Tx, play *Play, sign int64) error {
+	b := tx.Bucket(_rollupsBucket)
+	key := []byte(play.StartedAt.UTC().Format(_dayLayout))
+
+	r := newRollup()
+	if data := b.Get(key); data != nil {
+		if err := json.Unmarshal(data, r); err != nil {
+			return fmt.Errorf("unmarshal rollup %q: %w", key, err)
+		}
+	}
+
+	r.add(play, sign)
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("marshal rollup %q: %w", key, err)
+	}
+
+	return b.Put(key, data)
+}
+
+// StatsQuery represents the filters used to compute listening statistics.
+type StatsQuery struct {
+	// From filters out plays started before it (inclusive). Ignored if zero.
+	From time.Time
+	// To filters out plays started at or after it (exclusive). Defaults to now.
+	To time.Time
+	// Limit is the maximum number of top tracks, artists, albums and genres.
+	Limit int
+	// Location is used for the hour-of-day and day-of-week distributions and for streaks.
+	// Defaults to UTC. As the rollups are hourly in UTC, the plays of a UTC hour are counted
+	// in the local hour it starts in, which is off for locations with a fractional offset.
+	Location *time.Location
+}
+
+// Top represents a top track, artist, album or genre.
+type Top struct {
+	ID      string  `json:"id,omitempty"`
+	Name    string  `json:"name"`
+	Plays   int64   `json:"plays"`
+	Minutes float64 `json:"minutes"`
+}
+
+// Streaks represents the consecutive days with at least one play.
+type Streaks struct {
+	Current      int    `json:"current"`
+	Longest      int    `json:"longest"`
+	LongestStart string `json:"longest_start,omitempty"`
+	LongestEnd   string `json:"longest_end,omitempty"`
+}
+
+// Stats represents the listening statistics over a time window.
+type Stats struct {
+	From            time.Time             `json:"from"`
+	To              time.Time             `json:"to"`
+	Plays           int64                 `json:"plays"`
+	MinutesListened float64               `json:"minutes_listened"`
+	TopTracks       []Top                 `json:"top_tracks"`
+	TopArtists      []Top                 `json:"top_artists"`
+	TopAlbums       []Top                 `json:"top_albums"`
+	TopGenres       []Top                 `json:"top_genres"`
+	Streaks         Streaks               `json:"streaks"`
+	HourOfDay       [_hoursPerDay]float64 `json:"hour_of_day"`
+	DayOfWeek       [_daysPerWeek]float64 `json:"day_of_week"`
+}
+
+// Stats computes the listening statistics over a time window.
+// Whole days are read from the pre-computed daily rollups; only the plays of
+// partially covered days at the edges of the window are scanned.
+func (h *History) Stats(ctx context.Context, q StatsQuery) (*Stats, error) {
+	ctx, span := h.tracer.Start(ctx, "Stats")
+	defer span.End()
+
+	if q.To.IsZero() {
+		q.To = time.Now()
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = _defaultStatsLimit
+	} else if q.Limit > _maxStatsLimit {
+		q.Limit = _maxStatsLimit
+	}
+
+	if q.Location == nil {
+		q.Location = time.UTC
+	}
+
+	var days map[string]*rollup
+
+	if err := h.store.View(func(tx *bolt.Tx) error {
+		var err error
+		days, err = collectRollups(tx, q.From.UTC(), q.To.UTC())
+
+		return err
+	}); err != nil {
+		h.logger.ErrorwContext(ctx, "failed to compute stats", "error", err.Error())
+
+		return nil, err
+	}
+
+	total := newRollup()
+	for _, r := range days {
+		total.merge(r)
+	}
+
+	stats := Stats{
+		From:            q.From,
+		To:              q.To,
+		Plays:           total.Plays,
+		MinutesListened: minutes(total.ListenedMs),
+		TopTracks:       top(total.Tracks, q.Limit),
+		TopArtists:      top(total.Artists, q.Limit),
+		TopAlbums:       top(total.Albums, q.Limit),
+		TopGenres:       top(total.Genres, q.Limit),
+	}
+
+	active := make(map[string]struct{})
+	for day, r := range days {
+		start, err := time.Parse(_dayLayout, day)
+		if err != nil {
+			return nil, fmt.Errorf("parse day %q: %w", day, err)
+		}
+
+		for hour, listened := range r.Hours {
+			if listened <= 0 {
+				continue
+			}
+
+			local := start.Add(time.Duration(hour) * time.Hour).In(q.Location)
+			stats.HourOfDay[local.Hour()] += minutes(listened)
+			stats.DayOfWeek[local.Weekday()] += minutes(listened)
+			active[local.Format(_dayLayout)] = struct{}{}
+		}
+	}
+
+	stats.Streaks = streaks(active, q.To.In(q.Location))
+
+	return &stats, nil
+}
+
+// collectRollups returns the rollups of every day within [from, to).
+// The rollups of the days partially covered by the window are computed from the plays.
+func collectRollups(tx *bolt.Tx, from, to time.Time) (map[string]*rollup, error) {
+	days := make(map[string]*rollup)
+
+	// Days within [firstFull, lastFull) are fully covered by the window.
+	var firstFull, lastFull time.Time
+
+	if !from.IsZero() {
+		firstFull = truncateDay(from)
+		if !firstFull.Equal(from) {
+			firstFull = firstFull.AddDate(0, 0, 1)
+
+			r, err := scanRollup(tx, from, minTime(firstFull, to))
+			if err != nil {
+				return nil, err
+			}
+
+			days[from.Format(_dayLayout)] = r
+		}
+	}
+
+	lastFull = truncateDay(to)
+	if lastFull.Before(firstFull) {
+		return days, nil
+	}
+
+	if !lastFull.Equal(to) {
+		r, err := scanRollup(tx, lastFull, to)
+		if err != nil {
+			return nil, err
+		}
+
+		days[lastFull.Format(_dayLayout)] = r
+	}
+
+	c := tx.Bucket(_rollupsBucket).Cursor()
+	lower := []byte(firstFull.Format(_dayLayout))
+	if firstFull.IsZero() {
+		lower = nil
+	}
+
+	upper := []byte(lastFull.Format(_dayLayout))
+
+	k, v := c.Seek(lower)
+	if lower == nil {
+		k, v = c.First()
+	}
+
+	for ; k != nil && bytes.Compare(k, upper) < 0; k, v = c.Next() {
+		r := newRollup()
+		if err := json.Unmarshal(v, r); err != nil {
+			return nil, fmt.Errorf("unmarshal rollup %q: %w", k, err)
+		}
+
+		days[string(k)] = r
+	}
+
+	return days, nil
+}
+
+// scanRollup computes a rollup from the plays started within [from, to).
+func scanRollup(tx *bolt.Tx, from, to time.Time) (*rollup, error) {
+	r := newRollup()
+
+	c := tx.Bucket(_playsBucket).Cursor()
+	upper := timeKey(to)
+
+	for k, v := c.Seek(timeKey(from)); k != nil && bytes.Compare(k, upper) < 0; k, v = c.Next() {
+		var play Play
+		if err := json.Unmarshal(v, &play); err != nil {
+			return nil, fmt.Errorf("unmarshal play %q: %w", k, err)
+		}
+
+		r.add(&play, 1)
+	}
+
+	return r, nil
+}
+
+// top returns the top counters sorted by listened duration then plays.
+func top(counters map[string]*counter, limit int) []Top {
+	tops := make([]Top, 0, len(counters))
+	for _, c := range counters {
+		tops = append(tops, Top{
+			ID:      c.ID,
+			Name:    c.Name,
+			Plays:   c.Plays,
+			Minutes: minutes(c.ListenedMs),
+		})
+	}
+
+	sort.Slice(tops, func(i, j int) bool {
+		if tops[i].Minutes != tops[j].Minutes {
+			return tops[i].Minutes > tops[j].Minutes
+		}
+
+		if tops[i].Plays != tops[j].Plays {
+			return tops[i].Plays > tops[j].Plays
+		}
+
+		return tops[i].Name < tops[j].Name
+	})
+
+	if len(tops) > limit {
+		tops = tops[:limit]
+	}
+
+	return tops
+}
+
+// streaks computes the current and longest streaks of consecutive active days.
+func streaks(active map[string]struct{}, now time.Time) Streaks {
+	dates := make([]string, 0, len(active))
+	for day := range active {
+		dates = append(dates, day)
+	}
+
+	sort.Strings(dates)
+
+	var (
+		s     Streaks
+		run   int
+		start string
+		prev  time.Time
+	)
+
+	for _, day := range dates {
+		d, _ := time.Parse(_dayLayout, day) //nolint: errcheck
+
+		if run > 0 && d.Sub(prev) == _hoursPerDay*time.Hour {
+			run++
+		} else {
+			run = 1
+			start = day
+		}
+
+		if run > s.Longest {
+			s.Longest = run
+			s.LongestStart = start
+			s.LongestEnd = day
+		}
+
+		prev = d
+	}
+
+	// The current streak is still alive if the last active day is today or yesterday.
+	today, _ := time.Parse(_dayLayout, now.Format(_dayLayout)) //nolint: errcheck
+	if run > 0 && today.Sub(prev) <= _hoursPerDay*time.Hour {
+		s.Current = run
+	}
+
+	return s
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+
+	return b
+}
+
+func minutes(ms int64) float64 {
+	return float64(ms) / float64(time.Minute/time.Millisecond)
+}
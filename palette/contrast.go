@@ -0,0 +1,161 @@
+package palette
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/mgjules/spoty/colors"
+)
+
+// WCAG 2.x contrast thresholds.
+// See https://www.w3.org/TR/WCAG21/#contrast-minimum.
+const (
+	// ContrastAALarge is the minimum contrast of large text for level AA.
+	ContrastAALarge = 3
+	// ContrastAA is the minimum contrast of normal text for level AA.
+	ContrastAA = 4.5
+	// ContrastAAA is the minimum contrast of normal text for level AAA.
+	ContrastAAA = 7
+
+	// _darkLuminance is the luminance below which white text contrasts more than black text.
+	_darkLuminance = 0.179
+	// _minSecondaryAlpha is the minimum opacity of secondary text.
+	_minSecondaryAlpha = 0.54
+)
+
+// Background classifications.
+const (
+	Light = "light"
+	Dark  = "dark"
+)
+
+var (
+	_white = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	_black = color.RGBA{A: 0xff}
+)
+
+// Foreground represents a color to draw on top of a background with its contrast ratio.
+type Foreground struct {
+	RGBA  color.RGBA `json:"rgba"`
+	Hex   string     `json:"hex"`
+	Ratio float64    `json:"ratio"`
+	// AALarge tells whether the contrast meets WCAG AA for large text (>= 3).
+	AALarge bool `json:"aa_large"`
+	// AA tells whether the contrast meets WCAG AA for normal text (>= 4.5).
+	AA bool `json:"aa"`
+	// AAA tells whether the contrast meets WCAG AAA for normal text (>= 7).
+	AAA bool `json:"aaa"`
+}
+
+// Contrast represents the accessibility data of a background color.
+type Contrast struct {
+	// Luminance is the WCAG relative luminance of the color (0-1).
+	Luminance float64 `json:"luminance"`
+	// Classification is either light or dark.
+	Classification string `json:"classification"`
+	// WhiteRatio is the contrast ratio with white.
+	WhiteRatio float64 `json:"white_ratio"`
+	// BlackRatio is the contrast ratio with black.
+	BlackRatio float64 `json:"black_ratio"`
+	// Text is the recommended color of text: black or white, whichever contrasts the most.
+	Text Foreground `json:"text"`
+	// SecondaryText is the text color made as translucent as possible while meeting WCAG AA
+	// (or keeping the contrast of the text if it does not), flattened on the background.
+	SecondaryText Foreground `json:"secondary_text"`
+}
+
+// NewContrast computes the accessibility data of a background color.
+func NewContrast(bg color.RGBA) *Contrast {
+	lum := Luminance(bg)
+
+	c := Contrast{
+		Luminance:      round(lum, 4),
+		Classification: Light,
+		WhiteRatio:     round(contrastRatio(lum, 1), 2),
+		BlackRatio:     round(contrastRatio(lum, 0), 2),
+	}
+
+	text := _black
+	if lum < _darkLuminance {
+		c.Classification = Dark
+		text = _white
+	}
+
+	c.Text = newForeground(text, lum)
+	c.SecondaryText = newForeground(secondary(text, bg, lum, math.Min(c.Text.Ratio, ContrastAA)), lum)
+
+	return &c
+}
+
+// Luminance returns the WCAG relative luminance of a color.
+// See https://www.w3.org/TR/WCAG21/#dfn-relative-luminance.
+func Luminance(c color.RGBA) float64 {
+	return 0.2126*colors.Linear(c.R) + 0.7152*colors.Linear(c.G) + 0.0722*colors.Linear(c.B)
+}
+
+// ContrastRatio returns the WCAG contrast ratio between two colors (1-21).
+func ContrastRatio(a, b color.RGBA) float64 {
+	return contrastRatio(Luminance(a), Luminance(b))
+}
+
+func newForeground(fg color.RGBA, bgLuminance float64) Foreground {
+	ratio := contrastRatio(Luminance(fg), bgLuminance)
+
+	return Foreground{
+		RGBA:    fg,
+		Hex:     Hex(fg),
+		Ratio:   round(ratio, 2),
+		AALarge: ratio >= ContrastAALarge,
+		AA:      ratio >= ContrastAA,
+		AAA:     ratio >= ContrastAAA,
+	}
+}
+
+// secondary returns the most translucent version of the text color, flattened on the background,
+// whose contrast is still at least minRatio.
+func secondary(text, bg color.RGBA, bgLuminance, minRatio float64) color.RGBA {
+	// Contrast grows with opacity: binary search the lowest opacity meeting the ratio.
+	lo, hi := _minSecondaryAlpha, 1.0
+	if contrastRatio(Luminance(blend(text, bg, lo)), bgLuminance) >= minRatio {
+		return blend(text, bg, lo)
+	}
+
+	for i := 0; i < 16; i++ {
+		mid := (lo + hi) / 2
+		if contrastRatio(Luminance(blend(text, bg, mid)), bgLuminance) >= minRatio {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+
+	return blend(text, bg, hi)
+}
+
+// blend returns the foreground color drawn with the given opacity over the background.
+func blend(fg, bg color.RGBA, alpha float64) color.RGBA {
+	mix := func(f, b uint8) uint8 {
+		return uint8(math.Round(alpha*float64(f) + (1-alpha)*float64(b)))
+	}
+
+	return color.RGBA{
+		R: mix(fg.R, bg.R),
+		G: mix(fg.G, bg.G),
+		B: mix(fg.B, bg.B),
+		A: 0xff,
+	}
+}
+
+func contrastRatio(l1, l2 float64) float64 {
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+func round(v float64, decimals int) float64 {
+	p := math.Pow10(decimals)
+
+	return math.Round(v*p) / p
+}
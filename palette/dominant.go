@@ -0,0 +1,192 @@
+package palette
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/cenkalti/dominantcolor"
+	"github.com/mgjules/spoty/colors"
+)
+
+const (
+	// MaxBorder is the maximum share of an image ignored at each border.
+	MaxBorder = 0.45
+
+	// _dominantCandidates is the number of colors the dominant color is picked from.
+	_dominantCandidates = DefaultSize
+)
+
+// DominantOptions represents the options used to find the dominant color of an image.
+// The zero value keeps the original algorithm on the whole image.
+type DominantOptions struct {
+	// MinSaturation ignores colors whose HSL saturation is below it (0-1).
+	MinSaturation float64 `json:"min_saturation"`
+	// MinLightness ignores colors whose HSL lightness is below it (0-1).
+	MinLightness float64 `json:"min_lightness"`
+	// MaxLightness ignores colors whose HSL lightness is above it (0-1). 0 means 1.
+	MaxLightness float64 `json:"max_lightness"`
+	// SaturationWeight favours saturated colors: the population of a color is multiplied
+	// by 1 + SaturationWeight * saturation. 0 disables the weighting.
+	SaturationWeight float64 `json:"saturation_weight"`
+	// CenterCrop is the share of each dimension kept around the center (0-1). 0 means 1.
+	CenterCrop float64 `json:"center_crop"`
+	// Border is the share of each dimension ignored at each border (0-MaxBorder).
+	Border float64 `json:"border"`
+}
+
+// Validate checks that the options are within their bounds.
+func (o DominantOptions) Validate() error {
+	unit := func(name string, v float64) error {
+		if v < 0 || v > 1 {
+			return fmt.Errorf("%s must be between 0 and 1", name)
+		}
+
+		return nil
+	}
+
+	for _, err := range []error{
+		unit("min saturation", o.MinSaturation),
+		unit("min lightness", o.MinLightness),
+		unit("max lightness", o.MaxLightness),
+		unit("center crop", o.CenterCrop),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+
+	if o.MaxLightness > 0 && o.MaxLightness < o.MinLightness {
+		return errors.New("max lightness must be greater than min lightness")
+	}
+
+	if o.SaturationWeight < 0 {
+		return errors.New("saturation weight must be positive")
+	}
+
+	if o.Border < 0 || o.Border > MaxBorder {
+		return fmt.Errorf("border must be between 0 and %g", MaxBorder)
+	}
+
+	return nil
+}
+
+// Key returns a string identifying the options, e.g. for cache keys.
+func (o DominantOptions) Key() string {
+	return fmt.Sprintf("%g_%g_%g_%g_%g_%g",
+		o.MinSaturation, o.MinLightness, o.maxLightness(), o.SaturationWeight, o.centerCrop(), o.Border)
+}
+
+// filtered tells whether colors are filtered or weighted.
+func (o DominantOptions) filtered() bool {
+	return o.MinSaturation > 0 || o.MinLightness > 0 || o.maxLightness() < 1 || o.SaturationWeight > 0
+}
+
+func (o DominantOptions) maxLightness() float64 {
+	if o.MaxLightness <= 0 {
+		return 1
+	}
+
+	return o.MaxLightness
+}
+
+func (o DominantOptions) centerCrop() float64 {
+	if o.CenterCrop <= 0 {
+		return 1
+	}
+
+	return o.CenterCrop
+}
+
+// Dominant returns the dominant color of an image.
+// Without filtering nor weighting, it is the color found by dominantcolor.Find on the region.
+// Otherwise, it is the most populated (possibly saturation weighted) color of the palette of the
+// region among the colors within the thresholds; if there are none, the thresholds are ignored.
+func Dominant(img image.Image, opts DominantOptions) color.RGBA {
+	img = Region(img, opts)
+
+	if !opts.filtered() {
+		return dominantcolor.Find(img)
+	}
+
+	bins, _ := histogram(img)
+
+	kept := make([]*bin, 0, len(bins))
+	total := 0
+
+	for _, bn := range bins {
+		hsl := colors.ToHSL(bn.average())
+		if hsl.S < opts.MinSaturation || hsl.L < opts.MinLightness || hsl.L > opts.maxLightness() {
+			continue
+		}
+
+		kept = append(kept, bn)
+		total += bn.count
+	}
+
+	if total == 0 {
+		return dominantcolor.Find(img)
+	}
+
+	candidates := medianCut(kept, total, _dominantCandidates)
+
+	best, bestScore := 0, 0.0
+	for i := range candidates {
+		score := candidates[i].Population * (1 + opts.SaturationWeight*colors.ToHSL(candidates[i].RGBA).S)
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+
+	return candidates[best].RGBA
+}
+
+// Region returns the part of an image kept by the border exclusion and the center crop.
+// The border is excluded first; the center crop applies to what remains.
+func Region(img image.Image, opts DominantOptions) image.Image {
+	bounds := img.Bounds()
+
+	r := shrink(bounds, opts.Border)
+	r = shrink(r, (1-opts.centerCrop())/2)
+
+	if r == bounds {
+		return img
+	}
+
+	return &region{Image: img, bounds: r}
+}
+
+// shrink removes a share of each dimension of a rectangle at each side.
+// The resulting rectangle is at least 1x1.
+func shrink(r image.Rectangle, share float64) image.Rectangle {
+	if share <= 0 {
+		return r
+	}
+
+	dx := int(math.Min(float64(r.Dx())*share, float64(r.Dx()-1)/2))
+	dy := int(math.Min(float64(r.Dy())*share, float64(r.Dy()-1)/2))
+
+	return image.Rect(r.Min.X+dx, r.Min.Y+dy, r.Max.X-dx, r.Max.Y-dy)
+}
+
+// region restricts an image to some bounds.
+type region struct {
+	image.Image
+	bounds image.Rectangle
+}
+
+func (r *region) Bounds() image.Rectangle {
+	return r.bounds
+}
+
+// average returns the average color of the bin.
+func (b *bin) average() color.RGBA {
+	return color.RGBA{
+		R: uint8(b.sum[0] / b.count),
+		G: uint8(b.sum[1] / b.count),
+		B: uint8(b.sum[2] / b.count),
+		A: math.MaxUint8,
+	}
+}
@@ -0,0 +1,275 @@
+package palette
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/mgjules/spoty/colors"
+)
+
+const (
+	// DefaultSize is the default number of colors of a palette.
+	DefaultSize = 8
+	// MaxSize is the maximum number of colors of a palette.
+	MaxSize = 32
+
+	// _maxSamples is the maximum number of pixels sampled from an image.
+	_maxSamples = 160 * 160
+	// _quantizeBits is the number of bits kept per channel before clustering.
+	_quantizeBits = 5
+)
+
+// Color represents a color of a palette with its share of the image.
+type Color struct {
+	RGBA color.RGBA `json:"rgba"`
+	Hex  string     `json:"hex"`
+	*colors.Spaces
+	// Population is the share of the sampled pixels represented by the color (0-1).
+	Population float64   `json:"population"`
+	Contrast   *Contrast `json:"contrast"`
+}
+
+// Swatches represents the colors of a palette picked for their saturation and lightness,
+// in the style of Android's Palette. A swatch is nil if no color of the palette fits it.
+type Swatches struct {
+	Vibrant      *Color `json:"vibrant,omitempty"`
+	DarkVibrant  *Color `json:"dark_vibrant,omitempty"`
+	LightVibrant *Color `json:"light_vibrant,omitempty"`
+	Muted        *Color `json:"muted,omitempty"`
+	DarkMuted    *Color `json:"dark_muted,omitempty"`
+	LightMuted   *Color `json:"light_muted,omitempty"`
+}
+
+// Palette represents the main colors of an image, most populated first, and its swatches.
+type Palette struct {
+	Colors   []Color  `json:"colors"`
+	Swatches Swatches `json:"swatches"`
+}
+
+// Extract computes the palette of an image with at most size colors.
+// Colors are clustered with a median cut over a sample of the pixels.
+func Extract(img image.Image, size int) *Palette {
+	if size <= 0 {
+		size = DefaultSize
+	} else if size > MaxSize {
+		size = MaxSize
+	}
+
+	hist, total := histogram(img)
+	cs := medianCut(hist, total, size)
+
+	return &Palette{
+		Colors:   cs,
+		Swatches: swatches(cs),
+	}
+}
+
+// Hex returns the hex representation of a color (e.g. #1DB954).
+func Hex(c color.RGBA) string {
+	return colors.Hex(c)
+}
+
+// bin represents the sampled pixels sharing the same quantized color.
+type bin struct {
+	key   [3]uint8
+	count int
+	sum   [3]int
+}
+
+// index returns the position of the bin in the quantized color space.
+func (b *bin) index() int {
+	return int(b.key[0])<<(2*_quantizeBits) | int(b.key[1])<<_quantizeBits | int(b.key[2])
+}
+
+// histogram samples the opaque pixels of an image into quantized color bins.
+func histogram(img image.Image) ([]*bin, int) {
+	bounds := img.Bounds()
+
+	step := 1
+	if pixels := bounds.Dx() * bounds.Dy(); pixels > _maxSamples {
+		step = int(math.Ceil(math.Sqrt(float64(pixels) / _maxSamples)))
+	}
+
+	const shift = 8 - _quantizeBits
+
+	bins := make(map[[3]uint8]*bin)
+	total := 0
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			r, g, b, a := img.At(x, y).RGBA()
+			if a < 0x8000 {
+				continue
+			}
+
+			// Colors are un-premultiplied so that translucent pixels keep their hue.
+			r8, g8, b8 := uint8(r*0xffff/a>>8), uint8(g*0xffff/a>>8), uint8(b*0xffff/a>>8)
+			key := [3]uint8{r8 >> shift, g8 >> shift, b8 >> shift}
+
+			bn, ok := bins[key]
+			if !ok {
+				bn = &bin{key: key}
+				bins[key] = bn
+			}
+
+			bn.count++
+			bn.sum[0] += int(r8)
+			bn.sum[1] += int(g8)
+			bn.sum[2] += int(b8)
+			total++
+		}
+	}
+
+	list := make([]*bin, 0, len(bins))
+	for _, bn := range bins {
+		list = append(list, bn)
+	}
+
+	// Sorted for deterministic results.
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].index() < list[j].index()
+	})
+
+	return list, total
+}
+
+// box represents a set of bins within a region of the quantized color space.
+type box struct {
+	bins     []*bin
+	count    int
+	min, max [3]uint8
+}
+
+func newBox(bins []*bin) *box {
+	b := box{bins: bins}
+	b.min = [3]uint8{math.MaxUint8, math.MaxUint8, math.MaxUint8}
+
+	for _, bn := range bins {
+		b.count += bn.count
+
+		for c := 0; c < 3; c++ {
+			if bn.key[c] < b.min[c] {
+				b.min[c] = bn.key[c]
+			}
+
+			if bn.key[c] > b.max[c] {
+				b.max[c] = bn.key[c]
+			}
+		}
+	}
+
+	return &b
+}
+
+// volume returns the volume of the box in the quantized color space.
+func (b *box) volume() int {
+	v := 1
+	for c := 0; c < 3; c++ {
+		v *= int(b.max[c]-b.min[c]) + 1
+	}
+
+	return v
+}
+
+// longest returns the channel along which the box is the longest.
+func (b *box) longest() int {
+	longest := 0
+	for c := 1; c < 3; c++ {
+		if b.max[c]-b.min[c] > b.max[longest]-b.min[longest] {
+			longest = c
+		}
+	}
+
+	return longest
+}
+
+// split splits the box at the population median of its longest channel.
+func (b *box) split() (*box, *box) {
+	channel := b.longest()
+
+	sort.SliceStable(b.bins, func(i, j int) bool {
+		return b.bins[i].key[channel] < b.bins[j].key[channel]
+	})
+
+	half := b.count / 2
+	acc := 0
+
+	for i, bn := range b.bins[:len(b.bins)-1] {
+		acc += bn.count
+		if acc >= half {
+			return newBox(b.bins[:i+1]), newBox(b.bins[i+1:])
+		}
+	}
+
+	n := len(b.bins) - 1
+
+	return newBox(b.bins[:n]), newBox(b.bins[n:])
+}
+
+// average returns the average color of the box.
+func (b *box) average() color.RGBA {
+	var sum [3]int
+	for _, bn := range b.bins {
+		for c := 0; c < 3; c++ {
+			sum[c] += bn.sum[c]
+		}
+	}
+
+	return color.RGBA{
+		R: uint8(sum[0] / b.count),
+		G: uint8(sum[1] / b.count),
+		B: uint8(sum[2] / b.count),
+		A: math.MaxUint8,
+	}
+}
+
+// medianCut clusters the bins into at most size colors, most populated first.
+func medianCut(bins []*bin, total, size int) []Color {
+	if total == 0 {
+		return []Color{}
+	}
+
+	boxes := []*box{newBox(bins)}
+
+	for len(boxes) < size {
+		// Split the box with the largest population weighted volume.
+		best := -1
+		for i, b := range boxes {
+			if len(b.bins) < 2 {
+				continue
+			}
+
+			if best == -1 || b.count*b.volume() > boxes[best].count*boxes[best].volume() {
+				best = i
+			}
+		}
+
+		if best == -1 {
+			break
+		}
+
+		left, right := boxes[best].split()
+		boxes[best] = left
+		boxes = append(boxes, right)
+	}
+
+	result := make([]Color, 0, len(boxes))
+	for _, b := range boxes {
+		c := b.average()
+		result = append(result, Color{
+			RGBA:       c,
+			Hex:        Hex(c),
+			Spaces:     colors.Convert(c),
+			Population: float64(b.count) / float64(total),
+			Contrast:   NewContrast(c),
+		})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Population > result[j].Population
+	})
+
+	return result
+}
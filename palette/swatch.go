@@ -0,0 +1,82 @@
+package palette
+
+import (
+	"math"
+
+	"github.com/mgjules/spoty/colors"
+)
+
+// Weights of the swatch scoring, as in Android's Palette.
+const (
+	_saturationWeight = 0.24
+	_lightnessWeight  = 0.52
+	_populationWeight = 0.24
+)
+
+// target represents the saturation and lightness a swatch is looking for.
+type target struct {
+	minSaturation, saturation, maxSaturation float64
+	minLightness, lightness, maxLightness    float64
+}
+
+// Targets of the swatches, as in Android's Palette.
+var (
+	_lightVibrant = target{0.35, 1, 1, 0.55, 0.74, 1}
+	_vibrant      = target{0.35, 1, 1, 0.3, 0.5, 0.7}
+	_darkVibrant  = target{0.35, 1, 1, 0, 0.26, 0.45}
+	_lightMuted   = target{0, 0.3, 0.4, 0.55, 0.74, 1}
+	_muted        = target{0, 0.3, 0.4, 0.3, 0.5, 0.7}
+	_darkMuted    = target{0, 0.3, 0.4, 0, 0.26, 0.45}
+)
+
+// swatches picks the swatches among the candidate colors of a palette.
+// A color is used by a single swatch; vibrant swatches are picked first.
+func swatches(candidates []Color) Swatches {
+	var maxPopulation float64
+	for i := range candidates {
+		maxPopulation = math.Max(maxPopulation, candidates[i].Population)
+	}
+
+	used := make(map[int]bool)
+	pick := func(t target) *Color {
+		best, bestScore := -1, 0.0
+
+		for i := range candidates {
+			if used[i] {
+				continue
+			}
+
+			hsl := colors.ToHSL(candidates[i].RGBA)
+			s, l := hsl.S, hsl.L
+			if s < t.minSaturation || s > t.maxSaturation || l < t.minLightness || l > t.maxLightness {
+				continue
+			}
+
+			score := _saturationWeight*(1-math.Abs(s-t.saturation)) +
+				_lightnessWeight*(1-math.Abs(l-t.lightness)) +
+				_populationWeight*candidates[i].Population/maxPopulation
+
+			if best == -1 || score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+
+		if best == -1 {
+			return nil
+		}
+
+		used[best] = true
+		c := candidates[best]
+
+		return &c
+	}
+
+	return Swatches{
+		Vibrant:      pick(_vibrant),
+		LightVibrant: pick(_lightVibrant),
+		DarkVibrant:  pick(_darkVibrant),
+		Muted:        pick(_muted),
+		LightMuted:   pick(_lightMuted),
+		DarkMuted:    pick(_darkMuted),
+	}
+}
@@ -0,0 +1,123 @@
+package placeholder
+
+import (
+	"image"
+	"math"
+	"strings"
+
+	"github.com/mgjules/spoty/colors"
+)
+
+const (
+	// _blurHashComponents is the number of components along the longest side of an image.
+	_blurHashComponents = 4
+	// _base83 is the alphabet of BlurHash.
+	_base83 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+)
+
+// BlurHash returns the BlurHash of an image (see https://blurha.sh).
+// The number of components follows the aspect ratio of the image, with 4 along its longest side.
+// Large images should be downscaled first since every pixel is visited for every component.
+func BlurHash(img image.Image) string {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= 0 || h <= 0 {
+		return ""
+	}
+
+	cx, cy := _blurHashComponents, _blurHashComponents
+	if w > h {
+		cy = int(math.Max(1, math.Round(float64(_blurHashComponents*h)/float64(w))))
+	} else {
+		cx = int(math.Max(1, math.Round(float64(_blurHashComponents*w)/float64(h))))
+	}
+
+	// The image is converted to linear light once.
+	pixels := make([][3]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := nrgba(img, b.Min.X+x, b.Min.Y+y)
+			pixels[y*w+x] = [3]float64{colors.Linear(c.R), colors.Linear(c.G), colors.Linear(c.B)}
+		}
+	}
+
+	factors := make([][3]float64, 0, cx*cy)
+	for j := 0; j < cy; j++ {
+		for i := 0; i < cx; i++ {
+			factors = append(factors, blurHashFactor(pixels, w, h, i, j))
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(encode83((cx-1)+(cy-1)*9, 1))
+
+	dc, ac := factors[0], factors[1:]
+
+	maximum := 1.0
+	if len(ac) > 0 {
+		var actual float64
+		for _, f := range ac {
+			actual = math.Max(actual, math.Max(math.Abs(f[0]), math.Max(math.Abs(f[1]), math.Abs(f[2]))))
+		}
+
+		quantised := int(math.Max(0, math.Min(82, math.Floor(actual*166-0.5))))
+		maximum = float64(quantised+1) / 166
+		sb.WriteString(encode83(quantised, 1))
+	} else {
+		sb.WriteString(encode83(0, 1))
+	}
+
+	sb.WriteString(encode83(int(colors.Encode(dc[0]))<<16|int(colors.Encode(dc[1]))<<8|int(colors.Encode(dc[2])), 4))
+
+	for _, f := range ac {
+		quantise := func(v float64) int {
+			return int(math.Max(0, math.Min(18, math.Floor(signPow(v/maximum, 0.5)*9+9.5))))
+		}
+
+		sb.WriteString(encode83(quantise(f[0])*19*19+quantise(f[1])*19+quantise(f[2]), 2))
+	}
+
+	return sb.String()
+}
+
+// blurHashFactor returns the (i, j) cosine component of an image in linear light.
+func blurHashFactor(pixels [][3]float64, w, h, i, j int) [3]float64 {
+	var f [3]float64
+
+	for y := 0; y < h; y++ {
+		fy := math.Cos(math.Pi * float64(j) * float64(y) / float64(h))
+
+		for x := 0; x < w; x++ {
+			basis := fy * math.Cos(math.Pi*float64(i)*float64(x)/float64(w))
+			p := pixels[y*w+x]
+			f[0] += basis * p[0]
+			f[1] += basis * p[1]
+			f[2] += basis * p[2]
+		}
+	}
+
+	normalisation := 2.0
+	if i == 0 && j == 0 {
+		normalisation = 1
+	}
+
+	scale := normalisation / float64(w*h)
+
+	return [3]float64{f[0] * scale, f[1] * scale, f[2] * scale}
+}
+
+// encode83 encodes a value as length base 83 digits.
+func encode83(value, length int) string {
+	digits := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		digits[i] = _base83[value%83]
+		value /= 83
+	}
+
+	return string(digits)
+}
+
+// signPow raises the absolute value of v to exp, keeping its sign.
+func signPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
@@ -0,0 +1,98 @@
+// Package placeholder computes compact representations of images, such as BlurHash and ThumbHash,
+// which clients can render as blurry placeholders while the actual images load.
+package placeholder
+
+import (
+	"image"
+	"image/color"
+)
+
+// MaxThumbnailSize is the maximum width and height of the thumbnails the hashes are computed from.
+const MaxThumbnailSize = 100
+
+// Hashes represents the placeholders of an image.
+type Hashes struct {
+	BlurHash  string `json:"blurhash,omitempty"`
+	ThumbHash string `json:"thumbhash,omitempty"`
+}
+
+// New computes the placeholders of an image.
+// The image is downscaled once and both hashes are computed from the thumbnail.
+func New(img image.Image) *Hashes {
+	thumb := Thumbnail(img, MaxThumbnailSize)
+	if thumb == nil {
+		return nil
+	}
+
+	return &Hashes{
+		BlurHash:  BlurHash(thumb),
+		ThumbHash: ThumbHash(thumb),
+	}
+}
+
+// Thumbnail downscales an image so that it fits in size x size pixels, keeping its aspect ratio.
+// Each pixel of the thumbnail is the average of the pixels it covers. Smaller images are only copied.
+// It returns nil for empty images.
+func Thumbnail(img image.Image, size int) *image.NRGBA {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= 0 || h <= 0 || size <= 0 {
+		return nil
+	}
+
+	tw, th := w, h
+	if w > size || h > size {
+		if w >= h {
+			tw, th = size, h*size/w
+		} else {
+			tw, th = w*size/h, size
+		}
+	}
+
+	if tw < 1 {
+		tw = 1
+	}
+
+	if th < 1 {
+		th = 1
+	}
+
+	thumb := image.NewNRGBA(image.Rect(0, 0, tw, th))
+
+	for ty := 0; ty < th; ty++ {
+		y0, y1 := b.Min.Y+ty*h/th, b.Min.Y+(ty+1)*h/th
+
+		for tx := 0; tx < tw; tx++ {
+			x0, x1 := b.Min.X+tx*w/tw, b.Min.X+(tx+1)*w/tw
+
+			// Channels are averaged premultiplied so that transparent pixels do not bleed their color.
+			var r, g, bl, a, n uint64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					pr, pg, pb, pa := img.At(x, y).RGBA()
+					r, g, bl, a = r+uint64(pr), g+uint64(pg), bl+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+
+			avg := color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(bl / n),
+				A: uint16(a / n),
+			}
+			thumb.SetNRGBA(tx, ty, color.NRGBAModel.Convert(avg).(color.NRGBA))
+		}
+	}
+
+	return thumb
+}
+
+// nrgba returns the non-premultiplied color of a pixel.
+func nrgba(img image.Image, x, y int) color.NRGBA {
+	if n, ok := img.(*image.NRGBA); ok {
+		return n.NRGBAAt(x, y)
+	}
+
+	return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+}
@@ -0,0 +1,198 @@
+package placeholder
+
+import (
+	"encoding/base64"
+	"image"
+	"math"
+)
+
+// ThumbHash returns the base64 encoded ThumbHash of an image (see https://evanw.github.io/thumbhash).
+// Images larger than MaxThumbnailSize are downscaled first.
+func ThumbHash(img image.Image) string {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= 0 || h <= 0 {
+		return ""
+	}
+
+	if w > MaxThumbnailSize || h > MaxThumbnailSize {
+		return ThumbHash(Thumbnail(img, MaxThumbnailSize))
+	}
+
+	n := w * h
+
+	// Determine the average color.
+	var avgR, avgG, avgB, avgA float64
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := nrgba(img, b.Min.X+x, b.Min.Y+y)
+			alpha := float64(c.A) / 255
+			avgR += alpha / 255 * float64(c.R)
+			avgG += alpha / 255 * float64(c.G)
+			avgB += alpha / 255 * float64(c.B)
+			avgA += alpha
+		}
+	}
+
+	if avgA > 0 {
+		avgR /= avgA
+		avgG /= avgA
+		avgB /= avgA
+	}
+
+	hasAlpha := avgA < float64(n)
+
+	// Fewer luminance bits are used if there is alpha.
+	lLimit := 7.0
+	if hasAlpha {
+		lLimit = 5
+	}
+
+	longest := float64(w)
+	if h > w {
+		longest = float64(h)
+	}
+
+	lx := int(math.Max(1, jsRound(lLimit*float64(w)/longest)))
+	ly := int(math.Max(1, jsRound(lLimit*float64(h)/longest)))
+
+	// Convert the image to LPQA (luminance, yellow-blue, red-green and alpha),
+	// composited atop the average color.
+	l, p, q, a := make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := nrgba(img, b.Min.X+x, b.Min.Y+y)
+			alpha := float64(c.A) / 255
+			r := avgR*(1-alpha) + alpha/255*float64(c.R)
+			g := avgG*(1-alpha) + alpha/255*float64(c.G)
+			bl := avgB*(1-alpha) + alpha/255*float64(c.B)
+
+			i := y*w + x
+			l[i] = (r + g + bl) / 3
+			p[i] = (r+g)/2 - bl
+			q[i] = r - g
+			a[i] = alpha
+		}
+	}
+
+	lDC, lAC, lScale := thumbHashChannel(l, w, h, maxInt(3, lx), maxInt(3, ly))
+	pDC, pAC, pScale := thumbHashChannel(p, w, h, 3, 3)
+	qDC, qAC, qScale := thumbHashChannel(q, w, h, 3, 3)
+
+	var aDC, aScale float64
+	channels := [][]float64{lAC, pAC, qAC}
+	if hasAlpha {
+		var aAC []float64
+		aDC, aAC, aScale = thumbHashChannel(a, w, h, 5, 5)
+		channels = append(channels, aAC)
+	}
+
+	// Write the constants.
+	isLandscape := w > h
+
+	header24 := int(jsRound(63*lDC)) |
+		int(jsRound(31.5+31.5*pDC))<<6 |
+		int(jsRound(31.5+31.5*qDC))<<12 |
+		int(jsRound(31*lScale))<<18
+	if hasAlpha {
+		header24 |= 1 << 23
+	}
+
+	header16 := lx
+	if isLandscape {
+		header16 = ly
+	}
+	header16 |= int(jsRound(63*pScale))<<3 | int(jsRound(63*qScale))<<9
+	if isLandscape {
+		header16 |= 1 << 15
+	}
+
+	acStart := 5
+	if hasAlpha {
+		acStart = 6
+	}
+
+	var acCount int
+	for _, ac := range channels {
+		acCount += len(ac)
+	}
+
+	hash := make([]byte, acStart+(acCount+1)/2)
+	hash[0] = byte(header24)
+	hash[1] = byte(header24 >> 8)
+	hash[2] = byte(header24 >> 16)
+	hash[3] = byte(header16)
+	hash[4] = byte(header16 >> 8)
+
+	if hasAlpha {
+		hash[5] = byte(int(jsRound(15*aDC)) | int(jsRound(15*aScale))<<4)
+	}
+
+	// Write the varying factors, two per byte.
+	var index int
+	for _, ac := range channels {
+		for _, f := range ac {
+			hash[acStart+index>>1] |= byte(int(jsRound(15*f)) << ((index & 1) << 2))
+			index++
+		}
+	}
+
+	return base64.StdEncoding.EncodeToString(hash)
+}
+
+// thumbHashChannel encodes a channel using the DCT into its constant term and its varying terms,
+// normalized to 0-1, along with their scale.
+func thumbHashChannel(channel []float64, w, h, nx, ny int) (float64, []float64, float64) {
+	var (
+		dc, scale float64
+		ac        []float64
+	)
+
+	fx := make([]float64, w)
+
+	for cy := 0; cy < ny; cy++ {
+		for cx := 0; cx*ny < nx*(ny-cy); cx++ {
+			for x := 0; x < w; x++ {
+				fx[x] = math.Cos(math.Pi / float64(w) * float64(cx) * (float64(x) + 0.5))
+			}
+
+			var f float64
+			for y := 0; y < h; y++ {
+				fy := math.Cos(math.Pi / float64(h) * float64(cy) * (float64(y) + 0.5))
+				for x := 0; x < w; x++ {
+					f += channel[x+y*w] * fx[x] * fy
+				}
+			}
+
+			f /= float64(w * h)
+
+			if cx > 0 || cy > 0 {
+				ac = append(ac, f)
+				scale = math.Max(scale, math.Abs(f))
+			} else {
+				dc = f
+			}
+		}
+	}
+
+	if scale > 0 {
+		for i := range ac {
+			ac[i] = 0.5 + 0.5/scale*ac[i]
+		}
+	}
+
+	return dc, ac, scale
+}
+
+// jsRound rounds half up, like Math.round in JavaScript which the reference implementation uses.
+func jsRound(v float64) float64 {
+	return math.Floor(v + 0.5)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,223 @@
+package scheme
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/mgjules/spoty/colors"
+)
+
+// Tones are the standard tones of a tonal palette, from black (0) to white (100).
+var Tones = []int{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 95, 99, 100}
+
+// Chroma of the key colors of the palettes, in the spirit of Material's tonal spot scheme.
+const (
+	_achromaticChroma     = 0.02
+	_minPrimaryChroma     = 0.12
+	_secondaryChroma      = 0.04
+	_tertiaryChroma       = 0.06
+	_tertiaryHueRotation  = 60
+	_neutralChroma        = 0.01
+	_neutralVariantChroma = 0.02
+	_errorHue             = 27
+	_errorChroma          = 0.19
+)
+
+// TonalPalette represents the tones of a key color sharing its hue and chroma.
+type TonalPalette struct {
+	// Hue is the OKLCH hue of the key color (0-360).
+	Hue float64 `json:"hue"`
+	// Chroma is the OKLCH chroma of the key color. Tones may have less to fit in sRGB.
+	Chroma float64 `json:"chroma"`
+	// Tones maps each standard tone to its hex color.
+	Tones map[int]string `json:"tones"`
+}
+
+// NewTonalPalette computes the standard tones of a key color.
+func NewTonalPalette(hue, chroma float64) *TonalPalette {
+	p := TonalPalette{
+		Hue:    round(hue, 2),
+		Chroma: round(chroma, 4),
+		Tones:  make(map[int]string, len(Tones)),
+	}
+
+	for _, t := range Tones {
+		p.Tones[t] = colors.Hex(Tone(hue, chroma, t))
+	}
+
+	return &p
+}
+
+// Palettes represents the tonal palettes of a scheme.
+type Palettes struct {
+	Primary        *TonalPalette `json:"primary"`
+	Secondary      *TonalPalette `json:"secondary"`
+	Tertiary       *TonalPalette `json:"tertiary"`
+	Neutral        *TonalPalette `json:"neutral"`
+	NeutralVariant *TonalPalette `json:"neutral_variant"`
+	Error          *TonalPalette `json:"error"`
+}
+
+// Roles represents the colors of a scheme assigned to their role in a user interface.
+type Roles struct {
+	Primary            string `json:"primary"`
+	OnPrimary          string `json:"on_primary"`
+	PrimaryContainer   string `json:"primary_container"`
+	OnPrimaryContainer string `json:"on_primary_container"`
+
+	Secondary            string `json:"secondary"`
+	OnSecondary          string `json:"on_secondary"`
+	SecondaryContainer   string `json:"secondary_container"`
+	OnSecondaryContainer string `json:"on_secondary_container"`
+
+	Tertiary            string `json:"tertiary"`
+	OnTertiary          string `json:"on_tertiary"`
+	TertiaryContainer   string `json:"tertiary_container"`
+	OnTertiaryContainer string `json:"on_tertiary_container"`
+
+	Error            string `json:"error"`
+	OnError          string `json:"on_error"`
+	ErrorContainer   string `json:"error_container"`
+	OnErrorContainer string `json:"on_error_container"`
+
+	Background       string `json:"background"`
+	OnBackground     string `json:"on_background"`
+	Surface          string `json:"surface"`
+	OnSurface        string `json:"on_surface"`
+	SurfaceVariant   string `json:"surface_variant"`
+	OnSurfaceVariant string `json:"on_surface_variant"`
+	Outline          string `json:"outline"`
+	OutlineVariant   string `json:"outline_variant"`
+
+	InverseSurface   string `json:"inverse_surface"`
+	InverseOnSurface string `json:"inverse_on_surface"`
+	InversePrimary   string `json:"inverse_primary"`
+
+	Shadow string `json:"shadow"`
+	Scrim  string `json:"scrim"`
+}
+
+// Scheme represents a color scheme generated from a seed color.
+type Scheme struct {
+	// Seed is the hex color the scheme was generated from.
+	Seed     string   `json:"seed"`
+	Palettes Palettes `json:"palettes"`
+	Light    Roles    `json:"light"`
+	Dark     Roles    `json:"dark"`
+}
+
+// New generates a scheme from a seed color.
+// The palettes share the hue of the seed, except for the tertiary palette whose hue is
+// rotated and the error palette which is always red. Only the primary palette keeps
+// the chroma of the seed; it is raised so that dull seeds still yield an accent.
+// Achromatic seeds (black, white, grays) have no meaningful hue and yield a gray scheme.
+func New(seed color.RGBA) *Scheme {
+	key := colors.ToOKLCH(seed)
+
+	primary, secondary, tertiary := math.Max(key.C, _minPrimaryChroma), _secondaryChroma, _tertiaryChroma
+	if key.C < _achromaticChroma {
+		primary, secondary, tertiary = _neutralVariantChroma, _neutralChroma, _neutralVariantChroma
+	}
+
+	p := Palettes{
+		Primary:        NewTonalPalette(key.H, primary),
+		Secondary:      NewTonalPalette(key.H, secondary),
+		Tertiary:       NewTonalPalette(math.Mod(key.H+_tertiaryHueRotation, 360), tertiary),
+		Neutral:        NewTonalPalette(key.H, _neutralChroma),
+		NeutralVariant: NewTonalPalette(key.H, _neutralVariantChroma),
+		Error:          NewTonalPalette(_errorHue, _errorChroma),
+	}
+
+	return &Scheme{
+		Seed:     colors.Hex(seed),
+		Palettes: p,
+		Light:    lightRoles(&p),
+		Dark:     darkRoles(&p),
+	}
+}
+
+func lightRoles(p *Palettes) Roles {
+	return Roles{
+		Primary:            p.Primary.Tones[40],
+		OnPrimary:          p.Primary.Tones[100],
+		PrimaryContainer:   p.Primary.Tones[90],
+		OnPrimaryContainer: p.Primary.Tones[10],
+
+		Secondary:            p.Secondary.Tones[40],
+		OnSecondary:          p.Secondary.Tones[100],
+		SecondaryContainer:   p.Secondary.Tones[90],
+		OnSecondaryContainer: p.Secondary.Tones[10],
+
+		Tertiary:            p.Tertiary.Tones[40],
+		OnTertiary:          p.Tertiary.Tones[100],
+		TertiaryContainer:   p.Tertiary.Tones[90],
+		OnTertiaryContainer: p.Tertiary.Tones[10],
+
+		Error:            p.Error.Tones[40],
+		OnError:          p.Error.Tones[100],
+		ErrorContainer:   p.Error.Tones[90],
+		OnErrorContainer: p.Error.Tones[10],
+
+		Background:       p.Neutral.Tones[99],
+		OnBackground:     p.Neutral.Tones[10],
+		Surface:          p.Neutral.Tones[99],
+		OnSurface:        p.Neutral.Tones[10],
+		SurfaceVariant:   p.NeutralVariant.Tones[90],
+		OnSurfaceVariant: p.NeutralVariant.Tones[30],
+		Outline:          p.NeutralVariant.Tones[50],
+		OutlineVariant:   p.NeutralVariant.Tones[80],
+
+		InverseSurface:   p.Neutral.Tones[20],
+		InverseOnSurface: p.Neutral.Tones[95],
+		InversePrimary:   p.Primary.Tones[80],
+
+		Shadow: p.Neutral.Tones[0],
+		Scrim:  p.Neutral.Tones[0],
+	}
+}
+
+func darkRoles(p *Palettes) Roles {
+	return Roles{
+		Primary:            p.Primary.Tones[80],
+		OnPrimary:          p.Primary.Tones[20],
+		PrimaryContainer:   p.Primary.Tones[30],
+		OnPrimaryContainer: p.Primary.Tones[90],
+
+		Secondary:            p.Secondary.Tones[80],
+		OnSecondary:          p.Secondary.Tones[20],
+		SecondaryContainer:   p.Secondary.Tones[30],
+		OnSecondaryContainer: p.Secondary.Tones[90],
+
+		Tertiary:            p.Tertiary.Tones[80],
+		OnTertiary:          p.Tertiary.Tones[20],
+		TertiaryContainer:   p.Tertiary.Tones[30],
+		OnTertiaryContainer: p.Tertiary.Tones[90],
+
+		Error:            p.Error.Tones[80],
+		OnError:          p.Error.Tones[20],
+		ErrorContainer:   p.Error.Tones[30],
+		OnErrorContainer: p.Error.Tones[90],
+
+		Background:       p.Neutral.Tones[10],
+		OnBackground:     p.Neutral.Tones[90],
+		Surface:          p.Neutral.Tones[10],
+		OnSurface:        p.Neutral.Tones[90],
+		SurfaceVariant:   p.NeutralVariant.Tones[30],
+		OnSurfaceVariant: p.NeutralVariant.Tones[80],
+		Outline:          p.NeutralVariant.Tones[60],
+		OutlineVariant:   p.NeutralVariant.Tones[30],
+
+		InverseSurface:   p.Neutral.Tones[90],
+		InverseOnSurface: p.Neutral.Tones[20],
+		InversePrimary:   p.Primary.Tones[40],
+
+		Shadow: p.Neutral.Tones[0],
+		Scrim:  p.Neutral.Tones[0],
+	}
+}
+
+func round(v float64, decimals int) float64 {
+	p := math.Pow10(decimals)
+
+	return math.Round(v*p) / p
+}
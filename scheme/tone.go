@@ -0,0 +1,45 @@
+package scheme
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/mgjules/spoty/colors"
+)
+
+// Tone returns the color of the given hue and chroma (OKLCH) whose CIELAB lightness is tone (0-100).
+// Tones are measured in CIELAB, as in Material, so that their contrast only depends on their
+// difference: e.g. tones 40 and 100 always meet WCAG AA. The chroma is reduced to fit in sRGB.
+func Tone(hue, chroma float64, tone int) color.RGBA {
+	switch {
+	case tone <= 0:
+		return color.RGBA{A: math.MaxUint8}
+	case tone >= 100:
+		return color.RGBA{R: math.MaxUint8, G: math.MaxUint8, B: math.MaxUint8, A: math.MaxUint8}
+	}
+
+	// The CIELAB lightness grows with the OKLab lightness: binary search the latter.
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 24; i++ {
+		mid := (lo + hi) / 2
+		if lstar(colors.OKLCH{L: mid, C: chroma, H: hue}.ClampChroma()) < float64(tone) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return colors.OKLCH{L: (lo + hi) / 2, C: chroma, H: hue}.RGBA()
+}
+
+// lstar returns the CIELAB lightness (0-100) of a color.
+func lstar(c colors.OKLCH) float64 {
+	r, g, b := c.OKLab().LinearRGB()
+	y := 0.2126*r + 0.7152*g + 0.0722*b
+
+	if y <= 216.0/24389 {
+		return y * 24389 / 27
+	}
+
+	return 116*math.Cbrt(y) - 16
+}
@@ -0,0 +1,137 @@
+package spoty
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+)
+
+// _sniffLen is the number of bytes used to detect the type of an image.
+const _sniffLen = 512
+
+// Errors returned when an image is rejected.
+var (
+	ErrImageTooLarge    = errors.New("image too large")
+	ErrImageDimensions  = errors.New("image dimensions too large")
+	ErrUnsupportedImage = errors.New("unsupported image type")
+	ErrInvalidImage     = errors.New("invalid image")
+)
+
+// _supportedImageTypes are the sniffed content types of the images which can be decoded.
+var _supportedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+}
+
+// decodedImage represents a decoded image along with its format.
+type decodedImage struct {
+	image.Image
+	Format string
+}
+
+// ImageLimits represents the limits of an image processed from an untrusted source.
+type ImageLimits struct {
+	// MaxBytes is the maximum size of the encoded image.
+	MaxBytes int64
+	// MaxDimension is the maximum width and height of the image, in pixels.
+	MaxDimension int
+	// MaxPixels is the maximum number of pixels (width×height) of the image, which bounds
+	// the memory used to decode it. Zero disables it.
+	MaxPixels int64
+}
+
+// AnalyzeImage computes the colors of an encoded image (JPEG or PNG) read from r.
+// The type of the image is sniffed from its content and its size and dimensions are checked
+// against the configured limits before it is decoded by the image workers.
+func (s *Spoty) AnalyzeImage(ctx context.Context, r io.Reader, opts ImageOptions) (*Image, error) {
+	ctx, span := s.tracer.Start(ctx, "AnalyzeImage")
+	defer span.End()
+
+	data, err := readImage(r, s.analyzeLimits.MaxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var img Image
+
+	if poolErr := s.pool.Do(ctx, func() {
+		var decoded *decodedImage
+		if decoded, err = decodeImageData(data, s.analyzeLimits); err != nil {
+			return
+		}
+
+		bounds := decoded.Bounds()
+		img.Width, img.Height = bounds.Dx(), bounds.Dy()
+
+		colorize(&img, decoded.Image, s.withDefaults(opts))
+	}); poolErr != nil {
+		return nil, fmt.Errorf("schedule image: %w", poolErr)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &img, nil
+}
+
+// AnalyzeLimits returns the configured limits of analyzed images.
+func (s *Spoty) AnalyzeLimits() ImageLimits {
+	return s.analyzeLimits
+}
+
+// readImage reads an encoded image of at most maxBytes.
+func readImage(r io.Reader, maxBytes int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("read image: %w", err)
+	}
+
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrImageTooLarge, maxBytes)
+	}
+
+	return data, nil
+}
+
+// decodeImageData decodes an encoded image within limits.
+// Its type is sniffed and only its header is decoded before its dimensions are checked.
+func decodeImageData(data []byte, limits ImageLimits) (*decodedImage, error) {
+	sniffed := data
+	if len(sniffed) > _sniffLen {
+		sniffed = sniffed[:_sniffLen]
+	}
+
+	if contentType := http.DetectContentType(sniffed); !_supportedImageTypes[contentType] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedImage, contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, fmt.Errorf("%w: empty image", ErrInvalidImage)
+	}
+
+	if cfg.Width > limits.MaxDimension || cfg.Height > limits.MaxDimension {
+		return nil, fmt.Errorf("%w: %dx%d exceeds %dx%d",
+			ErrImageDimensions, cfg.Width, cfg.Height, limits.MaxDimension, limits.MaxDimension)
+	}
+
+	if pixels := int64(cfg.Width) * int64(cfg.Height); limits.MaxPixels > 0 && pixels > limits.MaxPixels {
+		return nil, fmt.Errorf("%w: %d pixels exceeds %d", ErrImageDimensions, pixels, limits.MaxPixels)
+	}
+
+	decoded, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	return &decodedImage{Image: decoded, Format: format}, nil
+}
@@ -0,0 +1,325 @@
+package spoty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iancoleman/strcase"
+	"github.com/zmb3/spotify"
+)
+
+const (
+	// MaxBatchSize is the maximum number of IDs processed by BatchImages.
+	MaxBatchSize = 50
+
+	// _catalogTTL is the lifetime of cached catalog items, which rarely change.
+	_catalogTTL = time.Hour
+	// _batchConcurrency is the maximum number of items processed at once by BatchImages.
+	_batchConcurrency = 4
+	// _tracksPerRequest and _albumsPerRequest are the maximum numbers of items retrieved
+	// at once from the batch endpoints of spotify.
+	_tracksPerRequest = 50
+	_albumsPerRequest = 20
+)
+
+// ErrNotFound is returned when an item does not exist on spotify.
+var ErrNotFound = errors.New("not found")
+
+// ResourceType represents the type of a catalog item whose images can be processed.
+type ResourceType string
+
+// Supported resource types.
+const (
+	ResourceTypeTrack ResourceType = "track"
+	ResourceTypeAlbum ResourceType = "album"
+)
+
+// ImagesResult represents the images of a catalog item processed in a batch.
+// Error is set instead of Images if the item could not be processed.
+type ImagesResult struct {
+	ID     spotify.ID   `json:"id"`
+	Type   ResourceType `json:"type"`
+	Images []Image      `json:"images,omitempty"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// Track returns a track of the catalog as an item.
+func (s *Spoty) Track(ctx context.Context, id spotify.ID) (*Item, error) {
+	ctx, span := s.tracer.Start(ctx, "Track")
+	defer span.End()
+
+	cacheTrackKey := "track_" + strcase.ToCamel(string(id))
+
+	cachedTrack, found := s.cache.Get(cacheTrackKey)
+	if found {
+		if cachedTrack, ok := cachedTrack.(*Item); ok {
+			s.logger.Ctx(ctx).Debugw("found cached track", "track", cachedTrack)
+
+			return cachedTrack, nil
+		}
+
+		s.logger.Ctx(ctx).Debugw("failed to parse cached track. retrieving fresh one...", "track", cachedTrack)
+	}
+
+	var track spotify.FullTrack
+
+	err := s.get(ctx, "tracks/"+url.PathEscape(string(id)), nil, &track)
+	if err != nil {
+		s.logger.ErrorwContext(ctx, "failed to retrieve track", "id", id, "error", err.Error())
+
+		return nil, catalogError(err)
+	}
+
+	item := &Item{Type: ItemTypeTrack, Track: &track}
+
+	s.cache.SetWithTTL(cacheTrackKey, item, 0, _catalogTTL)
+
+	return item, nil
+}
+
+// Album returns an album of the catalog.
+func (s *Spoty) Album(ctx context.Context, id spotify.ID) (*spotify.FullAlbum, error) {
+	ctx, span := s.tracer.Start(ctx, "Album")
+	defer span.End()
+
+	cacheAlbumKey := "album_" + strcase.ToCamel(string(id))
+
+	cachedAlbum, found := s.cache.Get(cacheAlbumKey)
+	if found {
+		if cachedAlbum, ok := cachedAlbum.(*spotify.FullAlbum); ok {
+			s.logger.Ctx(ctx).Debugw("found cached album", "album", cachedAlbum)
+
+			return cachedAlbum, nil
+		}
+
+		s.logger.Ctx(ctx).Debugw("failed to parse cached album. retrieving fresh one...", "album", cachedAlbum)
+	}
+
+	var album spotify.FullAlbum
+
+	err := s.get(ctx, "albums/"+url.PathEscape(string(id)), nil, &album)
+	if err != nil {
+		s.logger.ErrorwContext(ctx, "failed to retrieve album", "id", id, "error", err.Error())
+
+		return nil, catalogError(err)
+	}
+
+	s.cache.SetWithTTL(cacheAlbumKey, &album, 0, _catalogTTL)
+
+	return &album, nil
+}
+
+// AlbumImages returns the cover images of an album along with their dominant color.
+func (s *Spoty) AlbumImages(ctx context.Context, album *spotify.FullAlbum, opts ImageOptions) ([]Image, error) {
+	ctx, span := s.tracer.Start(ctx, "AlbumImages")
+	defer span.End()
+
+	if album == nil || album.ID == "" {
+		return nil, errors.New("invalid album")
+	}
+
+	return s.processImages(ctx, "album_"+strcase.ToCamel(string(album.ID)), album.Images, opts), nil
+}
+
+// BatchImages returns the images of up to MaxBatchSize catalog items of the same type.
+// The items which are not cached are retrieved with the batch endpoints of spotify, then their images
+// are processed concurrently; the failure of an item does not fail the others.
+func (s *Spoty) BatchImages(ctx context.Context, typ ResourceType, ids []spotify.ID, opts ImageOptions) ([]ImagesResult, error) {
+	ctx, span := s.tracer.Start(ctx, "BatchImages")
+	defer span.End()
+
+	if len(ids) > MaxBatchSize {
+		return nil, fmt.Errorf("too many ids: %d > %d", len(ids), MaxBatchSize)
+	}
+
+	var images func(ctx context.Context, i int) ([]Image, error)
+
+	switch typ {
+	case ResourceTypeTrack:
+		items, errs := s.tracks(ctx, ids)
+		images = func(ctx context.Context, i int) ([]Image, error) {
+			if errs[i] != nil {
+				return nil, errs[i]
+			}
+
+			return s.TrackImages(ctx, items[i], opts)
+		}
+	case ResourceTypeAlbum:
+		albums, errs := s.albums(ctx, ids)
+		images = func(ctx context.Context, i int) ([]Image, error) {
+			if errs[i] != nil {
+				return nil, errs[i]
+			}
+
+			return s.AlbumImages(ctx, albums[i], opts)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported resource type %q", typ)
+	}
+
+	results := make([]ImagesResult, len(ids))
+	sem := make(chan struct{}, _batchConcurrency)
+
+	var wg sync.WaitGroup
+
+	for i := range ids {
+		results[i] = ImagesResult{ID: ids[i], Type: typ}
+
+		wg.Add(1)
+		go func(i int, result *ImagesResult) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				result.Error = ctx.Err().Error()
+
+				return
+			}
+
+			imgs, err := images(ctx, i)
+			if err != nil {
+				result.Error = err.Error()
+
+				return
+			}
+
+			result.Images = imgs
+		}(i, &results[i])
+	}
+
+	wg.Wait()
+
+	return results, nil
+}
+
+// tracks returns the tracks of the catalog with the given IDs as items, along with the error of each
+// track which could not be retrieved. Both are in the order of the IDs.
+func (s *Spoty) tracks(ctx context.Context, ids []spotify.ID) ([]*Item, []error) {
+	items := make([]*Item, len(ids))
+	errs := make([]error, len(ids))
+
+	var missing []int
+	for i, id := range ids {
+		if cached, found := s.cache.Get("track_" + strcase.ToCamel(string(id))); found {
+			if item, ok := cached.(*Item); ok {
+				items[i] = item
+
+				continue
+			}
+		}
+
+		missing = append(missing, i)
+	}
+
+	batches(missing, _tracksPerRequest, func(batch []int) {
+		var resp struct {
+			Tracks []*spotify.FullTrack `json:"tracks"`
+		}
+
+		err := s.get(ctx, "tracks", batchQuery(ids, batch), &resp)
+		if err != nil {
+			s.logger.ErrorwContext(ctx, "failed to retrieve tracks", "error", err.Error())
+		}
+
+		for j, i := range batch {
+			switch {
+			case err != nil:
+				errs[i] = err
+			case j >= len(resp.Tracks) || resp.Tracks[j] == nil:
+				errs[i] = fmt.Errorf("%w: track %s", ErrNotFound, ids[i])
+			default:
+				items[i] = &Item{Type: ItemTypeTrack, Track: resp.Tracks[j]}
+				s.cache.SetWithTTL("track_"+strcase.ToCamel(string(ids[i])), items[i], 0, _catalogTTL)
+			}
+		}
+	})
+
+	return items, errs
+}
+
+// albums returns the albums of the catalog with the given IDs, along with the error of each
+// album which could not be retrieved. Both are in the order of the IDs.
+func (s *Spoty) albums(ctx context.Context, ids []spotify.ID) ([]*spotify.FullAlbum, []error) {
+	albums := make([]*spotify.FullAlbum, len(ids))
+	errs := make([]error, len(ids))
+
+	var missing []int
+	for i, id := range ids {
+		if cached, found := s.cache.Get("album_" + strcase.ToCamel(string(id))); found {
+			if album, ok := cached.(*spotify.FullAlbum); ok {
+				albums[i] = album
+
+				continue
+			}
+		}
+
+		missing = append(missing, i)
+	}
+
+	batches(missing, _albumsPerRequest, func(batch []int) {
+		var resp struct {
+			Albums []*spotify.FullAlbum `json:"albums"`
+		}
+
+		err := s.get(ctx, "albums", batchQuery(ids, batch), &resp)
+		if err != nil {
+			s.logger.ErrorwContext(ctx, "failed to retrieve albums", "error", err.Error())
+		}
+
+		for j, i := range batch {
+			switch {
+			case err != nil:
+				errs[i] = err
+			case j >= len(resp.Albums) || resp.Albums[j] == nil:
+				errs[i] = fmt.Errorf("%w: album %s", ErrNotFound, ids[i])
+			default:
+				albums[i] = resp.Albums[j]
+				s.cache.SetWithTTL("album_"+strcase.ToCamel(string(ids[i])), albums[i], 0, _catalogTTL)
+			}
+		}
+	})
+
+	return albums, errs
+}
+
+// batches calls fn with consecutive batches of at most size indexes.
+func batches(indexes []int, size int, fn func(batch []int)) {
+	for len(indexes) > 0 {
+		n := size
+		if len(indexes) < n {
+			n = len(indexes)
+		}
+
+		fn(indexes[:n])
+		indexes = indexes[n:]
+	}
+}
+
+// batchQuery returns the query of a batch endpoint retrieving the items of the IDs at the given indexes.
+func batchQuery(ids []spotify.ID, batch []int) url.Values {
+	parts := make([]string, 0, len(batch))
+	for _, i := range batch {
+		parts = append(parts, string(ids[i]))
+	}
+
+	return url.Values{"ids": {strings.Join(parts, ",")}}
+}
+
+// catalogError wraps the errors of spotify telling that an item does not exist with ErrNotFound.
+// Spotify answers with a bad request to malformed IDs.
+func catalogError(err error) error {
+	var sErr spotify.Error
+	if errors.As(err, &sErr) && (sErr.Status == http.StatusNotFound || sErr.Status == http.StatusBadRequest) {
+		return fmt.Errorf("%w: %s", ErrNotFound, sErr.Message)
+	}
+
+	return err
+}
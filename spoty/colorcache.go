@@ -0,0 +1,53 @@
+package spoty
+
+import (
+	"context"
+
+	"github.com/zmb3/spotify"
+)
+
+// _colorsKeyVersion is bumped whenever the colors of images are computed differently
+// so that the colors persisted by previous versions are ignored.
+const _colorsKeyVersion = "v2"
+
+// colorsKey returns the key under which the colors of an image are persisted.
+// Album images never change for a given URL. The options must have their defaults set.
+func colorsKey(url string, opts ImageOptions) string {
+	return "colors_" + _colorsKeyVersion + "_" + opts.key() + "_" + url
+}
+
+// persistedColors returns the persisted colors of the first of the images which has some, in order.
+func (s *Spoty) persistedColors(ctx context.Context, images []spotify.Image, order []int, opts ImageOptions) *Image {
+	if !s.colors.Enabled() {
+		return nil
+	}
+
+	ctx, span := s.tracer.Start(ctx, "PersistedColors")
+	defer span.End()
+
+	for _, i := range order {
+		var img Image
+
+		found, err := s.colors.Get(colorsKey(images[i].URL, opts), &img)
+		if err != nil {
+			s.logger.WarnwContext(ctx, "failed to read persisted colors", "error", err.Error(), "url", images[i].URL)
+
+			continue
+		}
+
+		if found {
+			s.logger.Ctx(ctx).Debugw("found persisted colors", "url", images[i].URL)
+
+			return &img
+		}
+	}
+
+	return nil
+}
+
+// persistColors persists the colors of an image.
+func (s *Spoty) persistColors(ctx context.Context, url string, opts ImageOptions, img *Image) {
+	if err := s.colors.Set(colorsKey(url, opts), img); err != nil {
+		s.logger.WarnwContext(ctx, "failed to persist colors", "error", err.Error(), "url", url)
+	}
+}
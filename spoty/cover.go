@@ -0,0 +1,233 @@
+package spoty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"sync"
+
+	"github.com/mgjules/spoty/cover"
+	"github.com/zmb3/spotify"
+)
+
+// _coverKeyVersion is bumped whenever covers are rendered differently so that the covers persisted by
+// previous versions are ignored.
+const _coverKeyVersion = "v1"
+
+// Errors returned when a cover cannot be rendered.
+var (
+	ErrNoCover = errors.New("no cover image")
+	// ErrInvalidOptions is returned when the options of a cover or of a bitmap are invalid.
+	ErrInvalidOptions = errors.New("invalid options")
+)
+
+// Cover represents a rendered cover along with its strong ETag.
+type Cover struct {
+	*cover.Image
+	ETag string
+}
+
+// Cover returns the cover of an item, resized and re-encoded.
+// Covers are rendered from the smallest image large enough for the requested size, reusing the last image
+// downloaded to compute colors. Both the images they are rendered from and the rendered covers are persisted.
+func (s *Spoty) Cover(ctx context.Context, item *Item, opts cover.Options) (*Cover, error) {
+	ctx, span := s.tracer.Start(ctx, "Cover")
+	defer span.End()
+
+	opts = opts.WithDefaults()
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: cover: %v", ErrInvalidOptions, err)
+	}
+
+	return s.renderCover(ctx, item, opts.Size, "cover_"+opts.Key(), func(img image.Image) (*cover.Image, error) {
+		return cover.Render(img, opts)
+	})
+}
+
+// Bitmap returns the cover of an item, resized and quantized to a palette.
+// It is rendered and persisted like the covers returned by Cover.
+func (s *Spoty) Bitmap(ctx context.Context, item *Item, opts cover.BitmapOptions) (*Cover, error) {
+	ctx, span := s.tracer.Start(ctx, "Bitmap")
+	defer span.End()
+
+	opts = opts.WithDefaults()
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: bitmap: %v", ErrInvalidOptions, err)
+	}
+
+	size := opts.Width
+	if opts.Height > size {
+		size = opts.Height
+	}
+
+	return s.renderCover(ctx, item, size, "bitmap_"+opts.Key(), func(img image.Image) (*cover.Image, error) {
+		return cover.RenderBitmap(img, opts)
+	})
+}
+
+// Render runs a CPU intensive job, such as drawing an image, on the image workers and waits for it to finish.
+func (s *Spoty) Render(ctx context.Context, job func()) error {
+	return s.pool.Do(ctx, job)
+}
+
+// renderCover renders the cover of an item from the smallest image whose sides are at least size pixels.
+// The rendered cover is persisted under a key starting with the given one, which identifies the rendering.
+func (s *Spoty) renderCover(
+	ctx context.Context,
+	item *Item,
+	size int,
+	key string,
+	render func(image.Image) (*cover.Image, error),
+) (*Cover, error) {
+	if item == nil {
+		return nil, errors.New("invalid item")
+	}
+
+	sources := item.Images()
+	if len(sources) == 0 {
+		return nil, ErrNoCover
+	}
+
+	url := sources[coverSource(sources, size)].URL
+	key = key + "_" + _coverKeyVersion + "_" + url
+
+	var rendered cover.Image
+
+	data, found, err := s.covers.GetBytes(key, &rendered)
+	if err != nil {
+		s.logger.WarnwContext(ctx, "failed to read persisted cover", "error", err.Error(), "url", url)
+	}
+
+	rendered.Data = data
+
+	if !found {
+		data, err := s.sourceImage(ctx, url)
+		if err != nil {
+			return nil, &imageError{reason: imageErrorReason(err), err: err}
+		}
+
+		if poolErr := s.pool.Do(ctx, func() {
+			var decoded *decodedImage
+			if decoded, err = decodeImageData(data, s.imageLimits); err != nil {
+				return
+			}
+
+			var img *cover.Image
+			if img, err = render(decoded.Image); err == nil {
+				rendered = *img
+			}
+		}); poolErr != nil {
+			return nil, &imageError{reason: "could not schedule album image", err: poolErr}
+		}
+
+		if err != nil {
+			return nil, &imageError{reason: imageErrorReason(err), err: err}
+		}
+
+		if err := s.covers.SetBytes(key, &rendered, rendered.Data); err != nil {
+			s.logger.WarnwContext(ctx, "failed to persist cover", "error", err.Error(), "url", url)
+		}
+	}
+
+	return &Cover{
+		Image: &rendered,
+		ETag:  cover.ETag(rendered.Data),
+	}, nil
+}
+
+// sourceImage returns a persisted encoded album image. Images not persisted yet are taken from the last
+// image downloaded to compute colors, or downloaded, and then persisted.
+func (s *Spoty) sourceImage(ctx context.Context, url string) ([]byte, error) {
+	data, found, err := s.covers.GetBytes(sourceKey(url), nil)
+	if err != nil {
+		s.logger.WarnwContext(ctx, "failed to read persisted album image", "error", err.Error(), "url", url)
+	}
+
+	if found {
+		return data, nil
+	}
+
+	if data = s.fetched.get(url); data == nil {
+		if err := s.checkImageURL(url); err != nil {
+			return nil, err
+		}
+
+		if poolErr := s.pool.Do(ctx, func() {
+			data, err = s.fetchImage(ctx, url)
+		}); poolErr != nil {
+			return nil, poolErr
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.covers.SetBytes(sourceKey(url), nil, data); err != nil {
+		s.logger.WarnwContext(ctx, "failed to persist album image", "error", err.Error(), "url", url)
+	}
+
+	return data, nil
+}
+
+// fetchedSource holds an encoded album image along with its URL.
+type fetchedSource struct {
+	mu   sync.Mutex
+	url  string
+	data []byte
+}
+
+// set replaces the album image.
+func (f *fetchedSource) set(url string, data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.url = url
+	f.data = data
+}
+
+// get returns the album image if it has the given URL, nil otherwise.
+func (f *fetchedSource) get(url string) []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.url != url {
+		return nil
+	}
+
+	return f.data
+}
+
+// sourceKey returns the key under which an encoded album image is persisted.
+func sourceKey(url string) string {
+	return "source_" + url
+}
+
+// coverSource returns the index of the image a cover of the given size should be rendered from:
+// the smallest image large enough or, failing that, the largest one.
+func coverSource(images []spotify.Image, size int) int {
+	best := 0
+
+	for i, img := range images {
+		side, bestSide := img.Width, images[best].Width
+		if img.Height < side {
+			side = img.Height
+		}
+
+		if images[best].Height < bestSide {
+			bestSide = images[best].Height
+		}
+
+		switch {
+		case bestSide < size:
+			if side > bestSide {
+				best = i
+			}
+		case side >= size && side < bestSide:
+			best = i
+		}
+	}
+
+	return best
+}
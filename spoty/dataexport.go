@@ -0,0 +1,252 @@
+package spoty
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mgjules/spoty/history"
+	"github.com/mgjules/spoty/json"
+)
+
+// _streamingHistoryLayout is the layout of the end times of the account data streaming history.
+const _streamingHistoryLayout = "2006-01-02 15:04"
+
+// ErrUnknownDataExport is returned for files that are not part of a spotify data export.
+var ErrUnknownDataExport = errors.New("unknown spotify data export file")
+
+// streamingHistoryEntry represents an entry of a StreamingHistory*.json file (account data).
+// Tracks and podcast episodes share the same files in older exports.
+type streamingHistoryEntry struct {
+	EndTime     string `json:"endTime"`
+	ArtistName  string `json:"artistName"`
+	TrackName   string `json:"trackName"`
+	PodcastName string `json:"podcastName"`
+	EpisodeName string `json:"episodeName"`
+	MsPlayed    int64  `json:"msPlayed"`
+}
+
+// extendedHistoryEntry represents an entry of an endsong_*.json or Streaming_History_Audio_*.json file
+// (extended streaming history).
+type extendedHistoryEntry struct {
+	TS              time.Time `json:"ts"`
+	Platform        string    `json:"platform"`
+	MsPlayed        int64     `json:"ms_played"`
+	TrackName       string    `json:"master_metadata_track_name"`
+	ArtistName      string    `json:"master_metadata_album_artist_name"`
+	AlbumName       string    `json:"master_metadata_album_album_name"`
+	TrackURI        string    `json:"spotify_track_uri"`
+	EpisodeName     string    `json:"episode_name"`
+	EpisodeShowName string    `json:"episode_show_name"`
+	EpisodeURI      string    `json:"spotify_episode_uri"`
+	ReasonEnd       string    `json:"reason_end"`
+	Skipped         *bool     `json:"skipped"`
+}
+
+// DataExportResult represents the plays read from a spotify data export file.
+type DataExportResult struct {
+	Plays []*history.Play
+	// Skipped is the number of entries that could not be converted to plays
+	// (e.g. nothing was played or the item is unknown).
+	Skipped int
+}
+
+// IsDataExportFile tells whether a file name is one of the streaming history files of a spotify data export.
+func IsDataExportFile(name string) bool {
+	base := strings.ToLower(filepath.Base(name))
+	if filepath.Ext(base) != ".json" {
+		return false
+	}
+
+	return strings.HasPrefix(base, "streaminghistory") ||
+		strings.HasPrefix(base, "endsong") ||
+		strings.HasPrefix(base, "streaming_history_audio")
+}
+
+// ReadDataExport reads the plays of a streaming history file from a spotify data export.
+// The kind of file is detected from its name.
+func ReadDataExport(name string, r io.Reader) (*DataExportResult, error) {
+	base := strings.ToLower(filepath.Base(name))
+
+	switch {
+	case strings.HasPrefix(base, "streaminghistory"):
+		var entries []streamingHistoryEntry
+		if err := json.NewDecoder(r).Decode(&entries); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", name, err)
+		}
+
+		var result DataExportResult
+		for i := range entries {
+			play, err := entries[i].play()
+			if err != nil {
+				return nil, fmt.Errorf("%s: entry %d: %w", name, i, err)
+			}
+
+			result.add(play)
+		}
+
+		return &result, nil
+	case strings.HasPrefix(base, "endsong"), strings.HasPrefix(base, "streaming_history_audio"):
+		var entries []extendedHistoryEntry
+		if err := json.NewDecoder(r).Decode(&entries); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", name, err)
+		}
+
+		durations := itemDurations(entries)
+
+		var result DataExportResult
+		for i := range entries {
+			result.add(entries[i].play(durations))
+		}
+
+		return &result, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDataExport, name)
+	}
+}
+
+func (r *DataExportResult) add(play *history.Play) {
+	if play == nil {
+		r.Skipped++
+
+		return
+	}
+
+	r.Plays = append(r.Plays, play)
+}
+
+// play converts the entry to a play. It returns nil if nothing was played.
+// The item ID is derived from the names as this kind of export does not provide it.
+func (e *streamingHistoryEntry) play() (*history.Play, error) {
+	if e.MsPlayed <= 0 {
+		return nil, nil
+	}
+
+	endedAt, err := time.Parse(_streamingHistoryLayout, e.EndTime)
+	if err != nil {
+		return nil, fmt.Errorf("parse end time: %w", err)
+	}
+
+	play := history.Play{
+		ItemType:   string(ItemTypeTrack),
+		Name:       e.TrackName,
+		Artists:    []history.Artist{{Name: e.ArtistName}},
+		StartedAt:  endedAt.Add(-time.Duration(e.MsPlayed) * time.Millisecond),
+		EndedAt:    endedAt,
+		ListenedMs: e.MsPlayed,
+	}
+
+	if e.EpisodeName != "" {
+		play.ItemType = string(ItemTypeEpisode)
+		play.Name = e.EpisodeName
+		play.Artists = []history.Artist{{Name: e.PodcastName}}
+		play.Album = history.Album{Name: e.PodcastName}
+	}
+
+	if play.Name == "" {
+		return nil, nil
+	}
+
+	play.ItemID = derivedItemID(play.Artists[0].Name, play.Name)
+
+	return &play, nil
+}
+
+// itemDurations estimates the durations of the items of extended streaming history entries, keyed by URI,
+// as the export does not provide them: an item played until its end lasts as long as it was played.
+func itemDurations(entries []extendedHistoryEntry) map[string]time.Duration {
+	durations := make(map[string]time.Duration)
+
+	for i := range entries {
+		e := &entries[i]
+		if e.ReasonEnd != "trackdone" || e.Skipped != nil && *e.Skipped {
+			continue
+		}
+
+		played := time.Duration(e.MsPlayed) * time.Millisecond
+		if uri := e.uri(); uri != "" && played > durations[uri] {
+			durations[uri] = played
+		}
+	}
+
+	return durations
+}
+
+// uri returns the URI of the track or episode of the entry.
+func (e *extendedHistoryEntry) uri() string {
+	if e.TrackURI != "" {
+		return e.TrackURI
+	}
+
+	return e.EpisodeURI
+}
+
+// play converts the entry to a play. It returns nil if nothing was played or the item is unknown.
+// The estimated durations of the items are used to classify the skips like the recorded ones.
+func (e *extendedHistoryEntry) play(durations map[string]time.Duration) *history.Play {
+	if e.MsPlayed <= 0 || e.TS.IsZero() {
+		return nil
+	}
+
+	play := history.Play{
+		StartedAt:  e.TS.Add(-time.Duration(e.MsPlayed) * time.Millisecond),
+		EndedAt:    e.TS,
+		ListenedMs: e.MsPlayed,
+		Outcome:    e.outcome(durations[e.uri()]),
+	}
+
+	if e.Platform != "" {
+		play.Device = &history.Device{Name: e.Platform}
+	}
+
+	switch {
+	case e.TrackURI != "" && e.TrackName != "":
+		play.ItemType = string(ItemTypeTrack)
+		play.ItemID = uriID(e.TrackURI)
+		play.Name = e.TrackName
+		play.Artists = []history.Artist{{Name: e.ArtistName}}
+		play.Album = history.Album{Name: e.AlbumName}
+	case e.EpisodeURI != "" && e.EpisodeName != "":
+		play.ItemType = string(ItemTypeEpisode)
+		play.ItemID = uriID(e.EpisodeURI)
+		play.Name = e.EpisodeName
+		play.Artists = []history.Artist{{Name: e.EpisodeShowName}}
+		play.Album = history.Album{Name: e.EpisodeShowName}
+	default:
+		return nil
+	}
+
+	return &play
+}
+
+// outcome returns the outcome of the play of an item of the given duration from the reason it ended, if known.
+// The duration is zero if unknown.
+func (e *extendedHistoryEntry) outcome(duration time.Duration) history.Outcome {
+	skipped := e.Skipped != nil && *e.Skipped
+
+	switch {
+	case e.ReasonEnd == "trackdone" && !skipped:
+		return history.OutcomeCompleted
+	case e.ReasonEnd == "fwdbtn" || e.ReasonEnd == "backbtn" || skipped:
+		return skipOutcome(duration, time.Duration(e.MsPlayed)*time.Millisecond)
+	default:
+		return ""
+	}
+}
+
+// uriID returns the ID of a spotify URI (e.g. spotify:track:<id>).
+func uriID(uri string) string {
+	return uri[strings.LastIndex(uri, ":")+1:]
+}
+
+// derivedItemID returns a stable item ID from an artist and an item name.
+func derivedItemID(artist, name string) string {
+	h := fnv.New64a()
+	h.Write([]byte(strings.ToLower(artist) + "\x00" + strings.ToLower(name))) //nolint: errcheck
+
+	return fmt.Sprintf("export:%016x", h.Sum64())
+}
@@ -0,0 +1,96 @@
+package spoty
+
+import (
+	"context"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/mgjules/spoty/history"
+	"github.com/mgjules/spoty/json"
+)
+
+// Topics on which events are published.
+const (
+	TopicPlayback = "spoty.playback"
+	TopicSessions = "spoty.sessions"
+)
+
+// EventType represents the type of an event.
+type EventType string
+
+// Supported playback event types.
+const (
+	EventTypePlayStarted  EventType = "play_started"
+	EventTypePlayFinished EventType = "play_finished"
+)
+
+// Supported session event types.
+const (
+	EventTypeSessionStarted EventType = "session_started"
+	EventTypeSessionEnded   EventType = "session_ended"
+)
+
+// PlaybackEvent represents a playback transition.
+// The play of a finished event holds its outcome (completed or skipped) and whether it was a replay.
+type PlaybackEvent struct {
+	Type       EventType     `json:"type"`
+	Play       *history.Play `json:"play"`
+	OccurredAt time.Time     `json:"occurred_at"`
+}
+
+func newPlaybackEvent(eventType EventType, play *history.Play, at time.Time) *PlaybackEvent {
+	return &PlaybackEvent{
+		Type:       eventType,
+		Play:       play,
+		OccurredAt: at,
+	}
+}
+
+// SessionEvent represents the start or the end of a listening session.
+// The session of a started event only holds its ID and start time.
+type SessionEvent struct {
+	Type       EventType        `json:"type"`
+	Session    *history.Session `json:"session"`
+	OccurredAt time.Time        `json:"occurred_at"`
+}
+
+func newSessionEvent(eventType EventType, session *history.Session, at time.Time) *SessionEvent {
+	return &SessionEvent{
+		Type:       eventType,
+		Session:    session,
+		OccurredAt: at,
+	}
+}
+
+// publishPlaybackEvent publishes a playback event.
+func (s *Spoty) publishPlaybackEvent(ctx context.Context, event *PlaybackEvent) {
+	s.publish(ctx, TopicPlayback, event)
+}
+
+// publishSessionEvent publishes a session event.
+func (s *Spoty) publishSessionEvent(ctx context.Context, event *SessionEvent) {
+	s.publish(ctx, TopicSessions, event)
+}
+
+// publish publishes a JSON encoded payload to a topic.
+// Failures are only logged as events are best effort.
+func (s *Spoty) publish(ctx context.Context, topic string, payload any) {
+	ctx, span := s.tracer.Start(ctx, "publish")
+	defer span.End()
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		s.logger.WarnwContext(ctx, "failed to marshal event", "error", err.Error(), "topic", topic)
+
+		return
+	}
+
+	if err := s.publisher.Publish(ctx, topic, message.NewMessage(watermill.NewUUID(), data)); err != nil {
+		s.logger.WarnwContext(ctx, "failed to publish event", "error", err.Error(), "topic", topic)
+
+		return
+	}
+
+	s.logger.Ctx(ctx).Debugw("published event", "topic", topic, "event", payload)
+}
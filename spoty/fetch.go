@@ -0,0 +1,208 @@
+package spoty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/zmb3/spotify"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// _minColorSourceSize is the smallest dimension of an image adequate to compute colors from.
+// Palettes sample at most 160x160 pixels.
+const _minColorSourceSize = 160
+
+// ErrImageHost is returned when an image is hosted outside of the allowed hosts.
+var ErrImageHost = errors.New("image host not allowed")
+
+// Fetcher fetches remote resources over HTTP.
+type Fetcher interface {
+	// Fetch performs a GET request and returns the body of a successful response.
+	// The body must be closed.
+	Fetch(ctx context.Context, url string) (io.ReadCloser, error)
+}
+
+// imageError represents the failure to process an image along with the reason shown to clients.
+type imageError struct {
+	reason string
+	err    error
+}
+
+func (e *imageError) Error() string {
+	return e.reason + ": " + e.err.Error()
+}
+
+func (e *imageError) Unwrap() error {
+	return e.err
+}
+
+// colorImage fetches an image and computes its colors.
+// The options must have their defaults set.
+func (s *Spoty) colorImage(ctx context.Context, url string, opts ImageOptions) (*Image, error) {
+	ctx, span := s.tracer.Start(ctx, "ColorImage")
+	defer span.End()
+
+	if err := s.checkImageURL(url); err != nil {
+		return nil, &imageError{reason: imageErrorReason(err), err: err}
+	}
+
+	data, err := s.fetchImage(ctx, url)
+	if err != nil {
+		return nil, &imageError{reason: imageErrorReason(err), err: err}
+	}
+
+	_, decodeSpan := s.tracer.Start(ctx, "DecodeImage")
+	decoded, err := decodeImageData(data, s.imageLimits)
+	if err == nil {
+		decodeSpan.SetAttributes(attribute.String("image.format", decoded.Format))
+	}
+	decodeSpan.End()
+
+	if err != nil {
+		return nil, &imageError{reason: imageErrorReason(err), err: err}
+	}
+
+	// The image is kept to render a cover from it without downloading it again.
+	s.fetched.set(url, data)
+
+	var img Image
+	colorize(&img, decoded.Image, opts)
+
+	return &img, nil
+}
+
+// fetchImage downloads an image through the fetcher, reading at most the configured number of bytes.
+func (s *Spoty) fetchImage(ctx context.Context, url string) ([]byte, error) {
+	ctx, span := s.tracer.Start(ctx, "FetchImage")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, _defaultTTL)
+	defer cancel()
+
+	body, err := s.fetcher.Fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close() //nolint: errcheck
+
+	data, err := readImage(body, s.imageLimits.MaxBytes)
+	span.SetAttributes(attribute.Int("image.bytes", len(data)))
+
+	return data, err
+}
+
+// checkImageURL makes sure an image is served over HTTPS by one of the allowed hosts.
+func (s *Spoty) checkImageURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrImageHost, err)
+	}
+
+	if u.Scheme != "https" || u.User != nil || (u.Port() != "" && u.Port() != "443") {
+		return fmt.Errorf("%w: %s", ErrImageHost, u.Redacted())
+	}
+
+	if !AllowedHost(s.imageHosts, u.Hostname()) {
+		return fmt.Errorf("%w: %s", ErrImageHost, u.Hostname())
+	}
+
+	return nil
+}
+
+// AllowedHost tells whether a host matches one of the allowed hosts.
+// An allowed host starting with "*." matches any of its subdomains and "*" matches any host.
+func AllowedHost(allowed []string, host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" {
+		return false
+	}
+
+	for _, a := range allowed {
+		a = strings.ToLower(strings.TrimSpace(a))
+
+		switch {
+		case a == "*":
+			return true
+		case strings.HasPrefix(a, "*."):
+			if strings.HasSuffix(host, a[1:]) {
+				return true
+			}
+		case a == host:
+			return true
+		}
+	}
+
+	return false
+}
+
+// imageErrorReason returns the reason shown to clients for the failure to process an album image.
+func imageErrorReason(err error) string {
+	switch {
+	case errors.Is(err, ErrImageHost):
+		return "album image host not allowed"
+	case errors.Is(err, ErrImageTooLarge):
+		return "album image too large"
+	case errors.Is(err, ErrImageDimensions):
+		return "album image dimensions too large"
+	case errors.Is(err, ErrUnsupportedImage):
+		return "unsupported album image type"
+	case errors.Is(err, ErrInvalidImage):
+		return "could not process album image"
+	default:
+		return "could not retrieve album image"
+	}
+}
+
+// colorSources returns the indexes of the images in the order they should be tried to compute colors:
+// the smallest adequate image first, then larger ones, then images of unknown size and finally
+// the inadequate ones, largest first.
+func colorSources(images []spotify.Image) []int {
+	size := func(i int) int {
+		if images[i].Width < images[i].Height {
+			return images[i].Width
+		}
+
+		return images[i].Height
+	}
+
+	rank := func(i int) int {
+		switch s := size(i); {
+		case s >= _minColorSourceSize:
+			return 0
+		case s <= 0:
+			return 1
+		default:
+			return 2
+		}
+	}
+
+	order := make([]int, len(images))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		ia, ib := order[a], order[b]
+
+		ra, rb := rank(ia), rank(ib)
+		if ra != rb {
+			return ra < rb
+		}
+
+		switch ra {
+		case 0:
+			return size(ia) < size(ib)
+		case 2:
+			return size(ia) > size(ib)
+		default:
+			return false
+		}
+	})
+
+	return order
+}
@@ -0,0 +1,171 @@
+package spoty
+
+import (
+	"fmt"
+
+	"github.com/mgjules/spoty/json"
+	"github.com/zmb3/spotify"
+)
+
+// ItemType represents the type of a playable item.
+type ItemType string
+
+// Supported playable item types.
+const (
+	ItemTypeTrack   ItemType = "track"
+	ItemTypeEpisode ItemType = "episode"
+)
+
+// Item represents a playable item: either a track or a podcast episode.
+type Item struct {
+	Type    ItemType             `json:"type"`
+	Track   *spotify.FullTrack   `json:"track,omitempty"`
+	Episode *spotify.EpisodePage `json:"episode,omitempty"`
+}
+
+// CurrentItem represents the current playing item as a bare track, as it was before episodes were
+// supported, along with the type of the item and the episode, if any.
+type CurrentItem struct {
+	*spotify.FullTrack
+	Type    ItemType             `json:"type"`
+	Episode *spotify.EpisodePage `json:"episode,omitempty"`
+}
+
+// Current returns the item as a CurrentItem.
+func (i *Item) Current() *CurrentItem {
+	return &CurrentItem{
+		FullTrack: i.Track,
+		Type:      i.Type,
+		Episode:   i.Episode,
+	}
+}
+
+// ID returns the spotify ID of the item.
+func (i *Item) ID() spotify.ID {
+	switch i.Type {
+	case ItemTypeTrack:
+		return i.Track.ID
+	case ItemTypeEpisode:
+		return i.Episode.ID
+	default:
+		return ""
+	}
+}
+
+// Name returns the name of the item.
+func (i *Item) Name() string {
+	switch i.Type {
+	case ItemTypeTrack:
+		return i.Track.Name
+	case ItemTypeEpisode:
+		return i.Episode.Name
+	default:
+		return ""
+	}
+}
+
+// Artists returns the names of the artists of the item.
+// For episodes, that is the publisher of the show.
+func (i *Item) Artists() []string {
+	switch i.Type {
+	case ItemTypeTrack:
+		names := make([]string, 0, len(i.Track.Artists))
+		for _, artist := range i.Track.Artists {
+			names = append(names, artist.Name)
+		}
+
+		return names
+	case ItemTypeEpisode:
+		return []string{i.Episode.Show.Publisher}
+	default:
+		return nil
+	}
+}
+
+// Collection returns the name of the album of a track or of the show of an episode.
+func (i *Item) Collection() string {
+	switch i.Type {
+	case ItemTypeTrack:
+		return i.Track.Album.Name
+	case ItemTypeEpisode:
+		return i.Episode.Show.Name
+	default:
+		return ""
+	}
+}
+
+// DurationMs returns the duration of the item in milliseconds.
+func (i *Item) DurationMs() int {
+	switch i.Type {
+	case ItemTypeTrack:
+		return i.Track.Duration
+	case ItemTypeEpisode:
+		return i.Episode.Duration_ms
+	default:
+		return 0
+	}
+}
+
+// Images returns the cover images of the item.
+// For tracks, those are the album images.
+// For episodes, those are the episode images, falling back to the show images.
+func (i *Item) Images() []spotify.Image {
+	switch i.Type {
+	case ItemTypeTrack:
+		return i.Track.Album.Images
+	case ItemTypeEpisode:
+		if len(i.Episode.Images) > 0 {
+			return i.Episode.Images
+		}
+
+		return i.Episode.Show.Images
+	default:
+		return nil
+	}
+}
+
+// currentlyPlaying mirrors spotify.CurrentlyPlaying but keeps the item raw
+// so that it can be decoded either as a track or as an episode.
+type currentlyPlaying struct {
+	Timestamp            int64                   `json:"timestamp"`
+	PlaybackContext      spotify.PlaybackContext `json:"context"`
+	Progress             int                     `json:"progress_ms"`
+	Playing              bool                    `json:"is_playing"`
+	CurrentlyPlayingType string                  `json:"currently_playing_type"`
+	RawItem              json.RawMessage         `json:"item"`
+}
+
+// playerState mirrors spotify.PlayerState using currentlyPlaying.
+type playerState struct {
+	currentlyPlaying
+	Device       spotify.PlayerDevice `json:"device"`
+	ShuffleState bool                 `json:"shuffle_state"`
+	RepeatState  string               `json:"repeat_state"`
+}
+
+// Item decodes the raw item according to the currently playing type.
+// It returns nil if nothing is playing or if the item is neither a track nor an episode (e.g. an ad).
+func (cp *currentlyPlaying) Item() (*Item, error) {
+	if len(cp.RawItem) == 0 || string(cp.RawItem) == "null" {
+		return nil, nil
+	}
+
+	switch ItemType(cp.CurrentlyPlayingType) {
+	case ItemTypeTrack:
+		var track spotify.FullTrack
+		if err := json.Unmarshal(cp.RawItem, &track); err != nil {
+			return nil, fmt.Errorf("decode track: %w", err)
+		}
+
+		return &Item{Type: ItemTypeTrack, Track: &track}, nil
+	case ItemTypeEpisode:
+		var episode spotify.EpisodePage
+		if err := json.Unmarshal(cp.RawItem, &episode); err != nil {
+			return nil, fmt.Errorf("decode episode: %w", err)
+		}
+
+		return &Item{Type: ItemTypeEpisode, Episode: &episode}, nil
+	default:
+		return nil, nil
+	}
+}
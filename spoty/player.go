@@ -0,0 +1,153 @@
+package spoty
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zmb3/spotify"
+)
+
+// Device represents the device on which playback is happening.
+type Device struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	IsActive      bool   `json:"is_active"`
+	IsRestricted  bool   `json:"is_restricted"`
+	VolumePercent int    `json:"volume_percent"`
+}
+
+// PlaybackContext represents the context (playlist, album, artist, ...) from
+// which the current item is being played.
+type PlaybackContext struct {
+	Type        string `json:"type"`
+	URI         string `json:"uri"`
+	Href        string `json:"href,omitempty"`
+	ExternalURL string `json:"external_url,omitempty"`
+}
+
+// PlaybackState represents the full playback state of the user along with the
+// dominant colors of the current item's cover images.
+// The current item is inlined: its track, if any, keeps the "track" field it had before episodes were supported.
+type PlaybackState struct {
+	Timestamp    int64            `json:"timestamp"`
+	IsPlaying    bool             `json:"is_playing"`
+	ProgressMs   int              `json:"progress_ms"`
+	ShuffleState bool             `json:"shuffle_state"`
+	RepeatState  string           `json:"repeat_state"`
+	Device       Device           `json:"device"`
+	Context      *PlaybackContext `json:"context,omitempty"`
+	*Item
+	Images []Image `json:"images,omitempty"`
+}
+
+// PlaybackState returns the current playback state.
+func (s *Spoty) PlaybackState(ctx context.Context) (*PlaybackState, error) {
+	ctx, span := s.tracer.Start(ctx, "PlaybackState")
+	defer span.End()
+
+	const cachePlaybackStateKey = "playback_state"
+
+	cachedState, found := s.cache.Get(cachePlaybackStateKey)
+	if found {
+		if cachedState, ok := cachedState.(*PlaybackState); ok {
+			s.logger.Ctx(ctx).Debugw("found cached playback state", "state", cachedState)
+
+			return cachedState, nil
+		}
+
+		s.logger.Ctx(ctx).Debugw("failed to parse cached playback state. retrieving fresh one...", "state", cachedState)
+	}
+
+	state, err := s.fetchPlaybackState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.Item != nil {
+		images, err := s.TrackImages(ctx, state.Item, ImageOptions{})
+		if err != nil {
+			s.logger.WarnwContext(ctx, "failed to retrieve track images", "error", err.Error())
+		}
+
+		state.Images = images
+	}
+
+	s.cache.SetWithTTL(cachePlaybackStateKey, state, 0, _defaultTTL)
+
+	return state, nil
+}
+
+// fetchPlaybackState retrieves the playback state from spotify and feeds it to the recorder.
+func (s *Spoty) fetchPlaybackState(ctx context.Context) (*PlaybackState, error) {
+	ctx, span := s.tracer.Start(ctx, "fetchPlaybackState")
+	defer span.End()
+
+	observedAt := time.Now()
+
+	var ps playerState
+	if err := s.get(ctx, "me/player", _additionalTypes, &ps); err != nil {
+		s.logger.ErrorwContext(ctx, "failed to retrieve playback state", "error", err.Error())
+
+		return nil, err
+	}
+
+	item, err := ps.Item()
+	if err != nil {
+		s.logger.ErrorwContext(ctx, "failed to decode playing item", "error", err.Error())
+
+		return nil, err
+	}
+
+	var state *PlaybackState
+	if ps.Device.ID != "" || item != nil {
+		state = newPlaybackState(&ps, item)
+	}
+
+	s.recorder.Observe(state, observedAt)
+
+	if state == nil {
+		return nil, errors.New("no active playback")
+	}
+
+	return state, nil
+}
+
+func newPlaybackState(ps *playerState, item *Item) *PlaybackState {
+	state := PlaybackState{
+		Timestamp:    ps.Timestamp,
+		IsPlaying:    ps.Playing,
+		ProgressMs:   ps.Progress,
+		ShuffleState: ps.ShuffleState,
+		RepeatState:  ps.RepeatState,
+		Device: Device{
+			ID:            string(ps.Device.ID),
+			Name:          ps.Device.Name,
+			Type:          ps.Device.Type,
+			IsActive:      ps.Device.Active,
+			IsRestricted:  ps.Device.Restricted,
+			VolumePercent: ps.Device.Volume,
+		},
+		Item: item,
+	}
+
+	state.Context = newPlaybackContext(&ps.PlaybackContext)
+
+	return &state
+}
+
+// newPlaybackContext returns a PlaybackContext from a spotify.PlaybackContext.
+// It returns nil if there is no context.
+func newPlaybackContext(pc *spotify.PlaybackContext) *PlaybackContext {
+	if pc.URI == "" {
+		return nil
+	}
+
+	return &PlaybackContext{
+		Type:        pc.Type,
+		URI:         string(pc.URI),
+		Href:        pc.Endpoint,
+		ExternalURL: pc.ExternalURLs["spotify"],
+	}
+}
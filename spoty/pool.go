@@ -0,0 +1,76 @@
+package spoty
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// errPoolClosed is returned when a job is submitted to a closed pool.
+var errPoolClosed = errors.New("worker pool closed")
+
+// workerPool runs jobs on a fixed number of goroutines.
+type workerPool struct {
+	jobs chan func()
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// newWorkerPool starts a pool of size workers.
+func newWorkerPool(size int) *workerPool {
+	p := workerPool{
+		jobs: make(chan func()),
+		done: make(chan struct{}),
+	}
+
+	p.wg.Add(size)
+	for i := 0; i < size; i++ {
+		go p.work()
+	}
+
+	return &p
+}
+
+func (p *workerPool) work() {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case job := <-p.jobs:
+			job()
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// Do runs a job on the pool and waits for it to finish.
+// It gives up if the job cannot be scheduled before the context is done or the pool is closed.
+func (p *workerPool) Do(ctx context.Context, job func()) error {
+	finished := make(chan struct{})
+
+	select {
+	case p.jobs <- func() {
+		defer close(finished)
+		job()
+	}:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.done:
+		return errPoolClosed
+	}
+
+	<-finished
+
+	return nil
+}
+
+// Close stops the workers once their current job is finished.
+func (p *workerPool) Close() {
+	p.once.Do(func() {
+		close(p.done)
+	})
+
+	p.wg.Wait()
+}
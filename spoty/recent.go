@@ -0,0 +1,139 @@
+package spoty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zmb3/spotify"
+)
+
+// _maxRecentLimit is the maximum number of items spotify returns per page.
+const _maxRecentLimit = 50
+
+// RecentOptions represents the options used to retrieve recently played tracks.
+// Only one of Before and After may be set.
+type RecentOptions struct {
+	// Limit is the maximum number of items to return (1-50).
+	Limit int
+	// Before is a unix timestamp in milliseconds. Returns items played before it.
+	Before int64
+	// After is a unix timestamp in milliseconds. Returns items played after it.
+	After int64
+	// Colors tells whether to compute the dominant colors of each item's images.
+	Colors bool
+}
+
+// Cursors represents the cursors used to navigate between pages.
+type Cursors struct {
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// RecentItem represents a recently played track.
+type RecentItem struct {
+	PlayedAt time.Time          `json:"played_at"`
+	Context  *PlaybackContext   `json:"context,omitempty"`
+	Track    *spotify.FullTrack `json:"track"`
+	Images   []Image            `json:"images,omitempty"`
+}
+
+// RecentlyPlayed represents a page of recently played tracks.
+type RecentlyPlayed struct {
+	Items   []RecentItem `json:"items"`
+	Cursors Cursors      `json:"cursors"`
+}
+
+type recentlyPlayed struct {
+	Items []struct {
+		Track    spotify.FullTrack       `json:"track"`
+		PlayedAt time.Time               `json:"played_at"`
+		Context  spotify.PlaybackContext `json:"context"`
+	} `json:"items"`
+	Cursors Cursors `json:"cursors"`
+}
+
+// RecentlyPlayed returns a page of recently played tracks.
+func (s *Spoty) RecentlyPlayed(ctx context.Context, opts RecentOptions) (*RecentlyPlayed, error) {
+	ctx, span := s.tracer.Start(ctx, "RecentlyPlayed")
+	defer span.End()
+
+	if opts.Before != 0 && opts.After != 0 {
+		return nil, errors.New("only one of before or after may be set")
+	}
+
+	if opts.Limit <= 0 || opts.Limit > _maxRecentLimit {
+		opts.Limit = _maxRecentLimit
+	}
+
+	cacheRecentKey := fmt.Sprintf("recent_%d_%d_%d_%t", opts.Limit, opts.Before, opts.After, opts.Colors)
+
+	cachedRecent, found := s.cache.Get(cacheRecentKey)
+	if found {
+		if cachedRecent, ok := cachedRecent.(*RecentlyPlayed); ok {
+			s.logger.Ctx(ctx).Debugw("found cached recently played", "recent", cachedRecent)
+
+			return cachedRecent, nil
+		}
+
+		s.logger.Ctx(ctx).Debugw("failed to parse cached recently played. retrieving fresh ones...", "recent", cachedRecent)
+	}
+
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(opts.Limit))
+	if opts.Before != 0 {
+		query.Set("before", strconv.FormatInt(opts.Before, 10))
+	}
+	if opts.After != 0 {
+		query.Set("after", strconv.FormatInt(opts.After, 10))
+	}
+
+	var rp recentlyPlayed
+	if err := s.get(ctx, "me/player/recently-played", query, &rp); err != nil {
+		s.logger.ErrorwContext(ctx, "failed to retrieve recently played tracks", "error", err.Error())
+
+		return nil, err
+	}
+
+	recent := RecentlyPlayed{
+		Items:   make([]RecentItem, len(rp.Items)),
+		Cursors: rp.Cursors,
+	}
+
+	for i := range rp.Items {
+		rpItem := &rp.Items[i]
+
+		recent.Items[i] = RecentItem{
+			PlayedAt: rpItem.PlayedAt,
+			Context:  newPlaybackContext(&rpItem.Context),
+			Track:    &rpItem.Track,
+		}
+	}
+
+	if opts.Colors {
+		var wg sync.WaitGroup
+		for i := range recent.Items {
+			wg.Add(1)
+			go func(item *RecentItem) {
+				defer wg.Done()
+
+				images, err := s.TrackImages(ctx, &Item{Type: ItemTypeTrack, Track: item.Track}, ImageOptions{})
+				if err != nil {
+					s.logger.WarnwContext(ctx, "failed to retrieve track images", "error", err.Error())
+				}
+
+				item.Images = images
+			}(&recent.Items[i])
+		}
+
+		wg.Wait()
+	}
+
+	s.cache.SetWithTTL(cacheRecentKey, &recent, 0, _defaultTTL)
+
+	return &recent, nil
+}
@@ -0,0 +1,543 @@
+package spoty
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/iancoleman/strcase"
+	"github.com/mgjules/spoty/history"
+	"github.com/mgjules/spoty/logger"
+	"github.com/zmb3/spotify"
+)
+
+// _restartThreshold is how far back the progress of the same item must jump
+// for it to be considered as played again from the start.
+const _restartThreshold = 10 * time.Second
+
+// _recorderQueueSize is the number of pending tasks beyond which the recorder drops
+// the events and session updates it queues.
+const _recorderQueueSize = 64
+
+// recorder records the playback transitions it observes into the history.
+// Observe only updates the state of the recorder. Enriching and storing the finished plays,
+// tracking the sessions and publishing the events are queued to a single worker, in order,
+// so that observers never wait for them.
+// The queue never blocks: consecutive session updates coalesce and, while the worker lags behind,
+// events and session updates are dropped. Finished plays are always queued.
+type recorder struct {
+	mu sync.Mutex
+
+	// current is the play in progress, if any, and item the item it plays.
+	current *history.Play
+	item    *Item
+	// last is the last observed playback state.
+	last *PlaybackState
+	// lastAt is when the last playback state was observed.
+	lastAt time.Time
+	// played tells whether the current play was ever observed playing.
+	played bool
+	// closed tells whether the recorder was flushed. Observations are then ignored.
+	closed bool
+
+	// pending is the queue of the worker, which is woken up through wake
+	// and closes done once the queue is drained after the recorder is flushed.
+	pending []task
+	wake    chan struct{}
+	done    chan struct{}
+
+	interval time.Duration
+	history  *history.History
+	// enrich adds extra metadata (e.g. genres) about its item to a play before it is recorded.
+	enrich func(ctx context.Context, play *history.Play, item *Item)
+	// emit publishes the playback events.
+	emit func(ctx context.Context, event *PlaybackEvent)
+	// sessions groups the plays into listening sessions. It is only used by the worker.
+	sessions *sessionTracker
+	logger   *logger.Logger
+}
+
+func newRecorder(
+	interval time.Duration,
+	history *history.History,
+	enrich func(ctx context.Context, play *history.Play, item *Item),
+	emit func(ctx context.Context, event *PlaybackEvent),
+	sessions *sessionTracker,
+	logger *logger.Logger,
+) *recorder {
+	r := recorder{
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		interval: interval,
+		history:  history,
+		enrich:   enrich,
+		emit:     emit,
+		sessions: sessions,
+		logger:   logger,
+	}
+
+	go r.work()
+
+	return &r
+}
+
+// task is a unit of work of the recorder worker.
+type task struct {
+	// name identifies the kind of task.
+	name string
+	// required tells whether the task is queued even if the queue is full.
+	required bool
+	// coalesce tells whether the task replaces a pending task of the same kind queued right before it.
+	coalesce bool
+	run      func(ctx context.Context)
+}
+
+// work runs the queued tasks until the recorder is flushed and the queue is drained.
+// Tasks outlive the observations which queued them, hence their own context.
+func (r *recorder) work() {
+	defer close(r.done)
+
+	ctx := context.Background()
+	for {
+		r.mu.Lock()
+		tasks, closed := r.pending, r.closed
+		r.pending = nil
+		r.mu.Unlock()
+
+		if len(tasks) == 0 {
+			if closed {
+				return
+			}
+
+			<-r.wake
+
+			continue
+		}
+
+		for _, t := range tasks {
+			t.run(ctx)
+		}
+	}
+}
+
+// queue queues a task without blocking and wakes the worker up. The lock must be held.
+func (r *recorder) queue(t task) {
+	if n := len(r.pending); t.coalesce && n > 0 && r.pending[n-1].name == t.name {
+		r.pending[n-1] = t
+
+		return
+	}
+
+	if !t.required && len(r.pending) >= _recorderQueueSize {
+		r.logger.Warnw("recorder queue is full, dropping task", "task", t.name)
+
+		return
+	}
+
+	r.pending = append(r.pending, t)
+
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Observe feeds a playback state observed at the given time to the recorder.
+// A nil state means that nothing is playing.
+func (r *recorder) Observe(state *PlaybackState, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed || (!r.lastAt.IsZero() && at.Before(r.lastAt)) {
+		return
+	}
+
+	var item *Item
+	if state != nil {
+		item = state.Item
+	}
+
+	var repeat bool
+
+	if r.current != nil {
+		// The same item goes on unless it was started again from the beginning, which is a replay.
+		repeat = item != nil && string(item.ID()) == r.current.ItemID
+
+		if repeat && !r.restarted(state) {
+			if r.advance(state, at) {
+				r.queueEvent(EventTypePlayStarted, at)
+			}
+
+			r.track(state, at)
+
+			return
+		}
+
+		r.queueFinish(state, at)
+	}
+
+	if item != nil {
+		r.start(state, at, repeat)
+
+		if r.played {
+			r.queueEvent(EventTypePlayStarted, at)
+		}
+	}
+
+	r.track(state, at)
+}
+
+// queueEvent queues an event about the current play. The lock must be held.
+func (r *recorder) queueEvent(eventType EventType, at time.Time) {
+	event := newPlaybackEvent(eventType, r.snapshot(), at)
+
+	r.queue(task{
+		name: "event",
+		run: func(ctx context.Context) {
+			r.emit(ctx, event)
+		},
+	})
+}
+
+// snapshot returns a copy of the current play, which the worker can use while the play goes on.
+// The lock must be held.
+func (r *recorder) snapshot() *history.Play {
+	play := *r.current
+
+	return &play
+}
+
+// track queues an update of the listening session with the observed state. The lock must be held.
+func (r *recorder) track(state *PlaybackState, at time.Time) {
+	if state != nil && state.IsPlaying && r.current != nil {
+		play := r.snapshot()
+		r.queue(task{
+			name:     "session.playing",
+			coalesce: true,
+			run: func(ctx context.Context) {
+				r.sessions.playing(ctx, play, at)
+			},
+		})
+
+		return
+	}
+
+	r.queue(task{
+		name:     "session.idle",
+		coalesce: true,
+		run: func(ctx context.Context) {
+			r.sessions.idle(ctx, at)
+		},
+	})
+}
+
+// Flush records the play in progress, if any, and ends the open session.
+// It waits for the queued tasks to be done. Later observations are ignored.
+func (r *recorder) Flush(ctx context.Context) error {
+	r.mu.Lock()
+
+	if !r.closed {
+		if r.current != nil {
+			r.queueFinish(nil, r.lastAt)
+		}
+
+		lastAt := r.lastAt
+		r.queue(task{
+			name:     "session.end",
+			required: true,
+			run: func(ctx context.Context) {
+				r.sessions.end(ctx, lastAt)
+			},
+		})
+
+		r.closed = true
+	}
+
+	r.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// queueFinish finishes the current play and queues its recording if it was ever played.
+// The lock must be held.
+func (r *recorder) queueFinish(next *PlaybackState, at time.Time) {
+	item := r.item
+
+	if finished := r.finish(next, at); finished != nil {
+		r.queue(task{
+			name:     "record",
+			required: true,
+			run: func(ctx context.Context) {
+				r.record(ctx, finished, item)
+			},
+		})
+	}
+}
+
+// record enriches, stores and publishes a finished play.
+func (r *recorder) record(ctx context.Context, play *history.Play, item *Item) {
+	r.enrich(ctx, play, item)
+
+	if err := r.history.Record(ctx, play); err != nil {
+		r.logger.WarnwContext(ctx, "failed to record play", "error", err.Error())
+
+		return
+	}
+
+	r.sessions.finished(play)
+
+	r.emit(ctx, newPlaybackEvent(EventTypePlayFinished, play, play.EndedAt))
+}
+
+// restarted tells whether the same item was started again from the beginning.
+func (r *recorder) restarted(state *PlaybackState) bool {
+	back := time.Duration(r.last.ProgressMs-state.ProgressMs) * time.Millisecond
+
+	return back > _restartThreshold && time.Duration(state.ProgressMs)*time.Millisecond <= r.interval+_restartThreshold
+}
+
+// advance accumulates the listened duration of the current play.
+// It returns true if the current play is observed playing for the first time.
+func (r *recorder) advance(state *PlaybackState, at time.Time) bool {
+	if r.last.IsPlaying && state.IsPlaying {
+		r.current.ListenedMs += listened(r.last.ProgressMs, state.ProgressMs, at.Sub(r.lastAt))
+	}
+
+	started := state.IsPlaying && !r.played
+	if state.IsPlaying {
+		r.played = true
+		r.current.EndedAt = at
+	}
+
+	r.last = state
+	r.lastAt = at
+
+	return started
+}
+
+// finish ends the current play, classifies it and returns it if it was ever played.
+// The next state, if any, is used to estimate the part played since the last observation.
+func (r *recorder) finish(next *PlaybackState, at time.Time) *history.Play {
+	play := r.current
+	played := r.played
+
+	r.current = nil
+	r.item = nil
+	r.played = false
+
+	t := transition{
+		last:   r.last,
+		lastAt: r.lastAt,
+		next:   next,
+		at:     at,
+	}
+
+	tail := t.tail(play.DurationMs)
+	if tail > 0 {
+		play.ListenedMs += tail.Milliseconds()
+		play.EndedAt = r.lastAt.Add(tail)
+	}
+
+	play.Outcome = t.classify(play.DurationMs, time.Duration(r.last.ProgressMs)*time.Millisecond+tail)
+
+	if !played {
+		return nil
+	}
+
+	return play
+}
+
+// start starts a new play from the given state, flagging it if it replays the previous item.
+func (r *recorder) start(state *PlaybackState, at time.Time, repeat bool) {
+	progress := time.Duration(state.ProgressMs) * time.Millisecond
+
+	play := newPlay(state)
+	play.Repeat = repeat
+	play.StartedAt = at.Add(-progress)
+	play.EndedAt = at
+
+	if state.IsPlaying {
+		// Only count what could have been played since the previous observation.
+		play.ListenedMs = clamp(progress.Milliseconds(), 0, r.interval.Milliseconds())
+	}
+
+	r.current = play
+	r.item = state.Item
+	r.played = state.IsPlaying
+	r.last = state
+	r.lastAt = at
+}
+
+// listened returns the listened duration between two progress positions,
+// bounded by the wall-clock time elapsed to discard seeks.
+func listened(from, to int, elapsed time.Duration) int64 {
+	return clamp(int64(to-from), 0, elapsed.Milliseconds()+_restartThreshold.Milliseconds())
+}
+
+func clamp(v, lo, hi int64) int64 {
+	if v < lo {
+		return lo
+	}
+
+	if v > hi {
+		return hi
+	}
+
+	return v
+}
+
+// newPlay returns a new play from a playback state.
+func newPlay(state *PlaybackState) *history.Play {
+	item := state.Item
+
+	play := history.Play{
+		ItemType: string(item.Type),
+		ItemID:   string(item.ID()),
+		Name:     item.Name(),
+		Device: &history.Device{
+			ID:   state.Device.ID,
+			Name: state.Device.Name,
+			Type: state.Device.Type,
+		},
+	}
+
+	if images := item.Images(); len(images) > 0 {
+		play.ImageURL = images[0].URL
+	}
+
+	switch item.Type {
+	case ItemTypeTrack:
+		play.DurationMs = int64(item.Track.Duration)
+		play.Album = history.Album{
+			ID:   string(item.Track.Album.ID),
+			Name: item.Track.Album.Name,
+		}
+
+		for _, artist := range item.Track.Artists {
+			play.Artists = append(play.Artists, history.Artist{
+				ID:   string(artist.ID),
+				Name: artist.Name,
+			})
+		}
+	case ItemTypeEpisode:
+		play.DurationMs = int64(item.Episode.Duration_ms)
+		play.Album = history.Album{
+			ID:   string(item.Episode.Show.ID),
+			Name: item.Episode.Show.Name,
+		}
+		play.Artists = []history.Artist{
+			{Name: item.Episode.Show.Publisher},
+		}
+	}
+
+	if state.Context != nil {
+		play.Context = &history.Context{
+			Type: state.Context.Type,
+			URI:  state.Context.URI,
+		}
+	}
+
+	return &play
+}
+
+// enrichPlay adds the dominant color of the cover and, for a track, the genres of its artists to the play.
+// The color is computed like the ones of TrackImages, sharing their caches.
+func (s *Spoty) enrichPlay(ctx context.Context, play *history.Play, item *Item) {
+	if item != nil && len(item.Images()) > 0 {
+		play.Color = s.itemColor(ctx, item)
+	}
+
+	if play.ItemType != string(ItemTypeTrack) {
+		return
+	}
+
+	ids := make([]spotify.ID, 0, len(play.Artists))
+	for _, artist := range play.Artists {
+		if artist.ID != "" {
+			ids = append(ids, spotify.ID(artist.ID))
+		}
+	}
+
+	play.Genres = s.artistGenres(ctx, ids)
+}
+
+// artistGenres returns the distinct genres of the given artists.
+func (s *Spoty) artistGenres(ctx context.Context, ids []spotify.ID) []string {
+	ctx, span := s.tracer.Start(ctx, "artistGenres")
+	defer span.End()
+
+	var (
+		genres  []string
+		missing []spotify.ID
+	)
+
+	seen := make(map[string]struct{})
+	add := func(gs []string) {
+		for _, g := range gs {
+			if _, ok := seen[g]; !ok {
+				seen[g] = struct{}{}
+				genres = append(genres, g)
+			}
+		}
+	}
+
+	for _, id := range ids {
+		cached, found := s.cache.Get(artistGenresKey(id))
+		if cachedGenres, ok := cached.([]string); found && ok {
+			add(cachedGenres)
+
+			continue
+		}
+
+		missing = append(missing, id)
+	}
+
+	if len(missing) == 0 || !s.IsAuth() {
+		return genres
+	}
+
+	artists, err := s.spotifyClient().GetArtists(missing...)
+	if err != nil {
+		s.logger.WarnwContext(ctx, "failed to retrieve artists genres", "error", err.Error())
+
+		return genres
+	}
+
+	for _, artist := range artists {
+		if artist == nil {
+			continue
+		}
+
+		s.cache.SetWithTTL(artistGenresKey(artist.ID), artist.Genres, 0, _genresTTL)
+		add(artist.Genres)
+	}
+
+	return genres
+}
+
+// itemColor returns the hex dominant color of the cover of an item or an empty string if it could not be computed.
+func (s *Spoty) itemColor(ctx context.Context, item *Item) string {
+	images, err := s.TrackImages(ctx, item, ImageOptions{})
+	if err != nil {
+		s.logger.WarnwContext(ctx, "could not process cover image", "error", err.Error())
+
+		return ""
+	}
+
+	for i := range images {
+		if images[i].Error == "" {
+			return images[i].Hex
+		}
+	}
+
+	return ""
+}
+
+func artistGenresKey(id spotify.ID) string {
+	return "artist_" + strcase.ToCamel(string(id)) + "_genres"
+}
@@ -0,0 +1,85 @@
+package spoty
+
+import (
+	"context"
+	"time"
+
+	"github.com/mgjules/spoty/history"
+)
+
+// sessionTracker follows the listening session in progress and emits its start and end.
+type sessionTracker struct {
+	gap time.Duration
+
+	// builder accumulates the finished plays of the open session. It is nil if no session is open.
+	builder *history.SessionBuilder
+	// startedAt is when the open session started.
+	startedAt time.Time
+	// lastActive is when something was last observed playing.
+	lastActive time.Time
+
+	emit func(ctx context.Context, event *SessionEvent)
+}
+
+func newSessionTracker(gap time.Duration, emit func(ctx context.Context, event *SessionEvent)) *sessionTracker {
+	if gap <= 0 {
+		gap = history.DefaultSessionGap
+	}
+
+	return &sessionTracker{
+		gap:  gap,
+		emit: emit,
+	}
+}
+
+// playing notifies the tracker that a play is observed playing.
+// A session is started if none is open or if the break since the last activity is too long.
+func (t *sessionTracker) playing(ctx context.Context, play *history.Play, at time.Time) {
+	if t.builder != nil && at.Sub(t.lastActive) > t.gap {
+		t.end(ctx, t.lastActive)
+	}
+
+	if t.builder == nil {
+		t.builder = history.NewSessionBuilder(t.gap)
+		t.startedAt = play.StartedAt
+
+		t.emit(ctx, newSessionEvent(EventTypeSessionStarted, history.NewSession(play.StartedAt), at))
+	}
+
+	t.lastActive = at
+}
+
+// finished adds a finished play to the open session, if any.
+func (t *sessionTracker) finished(play *history.Play) {
+	if t.builder == nil {
+		return
+	}
+
+	t.builder.Add(play)
+}
+
+// idle notifies the tracker that nothing is playing.
+// The open session is ended once the break is too long.
+func (t *sessionTracker) idle(ctx context.Context, at time.Time) {
+	if t.builder != nil && at.Sub(t.lastActive) > t.gap {
+		t.end(ctx, t.lastActive)
+	}
+}
+
+// end ends the open session, if any.
+func (t *sessionTracker) end(ctx context.Context, at time.Time) {
+	if t.builder == nil {
+		return
+	}
+
+	session := t.builder.Session()
+	if session == nil {
+		session = history.NewSession(t.startedAt)
+		session.EndedAt = at
+		session.DurationMs = at.Sub(t.startedAt).Milliseconds()
+	}
+
+	t.builder = nil
+
+	t.emit(ctx, newSessionEvent(EventTypeSessionEnded, session, at))
+}
@@ -8,7 +8,9 @@ import (
 	"image/color"
 	_ "image/jpeg"
 	_ "image/png"
+	"io"
 	"net/http"
+	"net/url"
 	"sync"
 	"time"
 
@@ -16,39 +18,86 @@ import (
 	"github.com/google/uuid"
 	"github.com/iancoleman/strcase"
 	"github.com/mgjules/spoty/cache"
+	"github.com/mgjules/spoty/colors"
 	"github.com/mgjules/spoty/config"
+	"github.com/mgjules/spoty/diskcache"
 	"github.com/mgjules/spoty/health"
+	"github.com/mgjules/spoty/history"
+	"github.com/mgjules/spoty/json"
 	"github.com/mgjules/spoty/logger"
+	"github.com/mgjules/spoty/palette"
+	"github.com/mgjules/spoty/placeholder"
 	"github.com/mgjules/spoty/tracer"
+	"github.com/mgjules/spoty/transport/messenger"
 	"github.com/zmb3/spotify"
 	"go.uber.org/fx"
 )
 
-const _defaultTTL = 5 * time.Second
+const (
+	_defaultTTL        = 5 * time.Second
+	_genresTTL         = 24 * time.Hour
+	_spotifyAPIBaseURL = "https://api.spotify.com/v1/"
+)
+
+// _additionalTypes asks spotify to also return podcast episodes as playing items.
+var _additionalTypes = url.Values{"additional_types": []string{"track,episode"}}
 
 // Module exported for initialising a new Spoty service.
 var Module = fx.Options(
 	fx.Provide(New),
 )
 
-// Image represents an image with its dominant color.
+// Image represents an image with its dominant color in several color spaces, the
+// accessibility data of the dominant color, its placeholders and, optionally, its palette.
 type Image struct {
-	URL      string     `json:"url"`
-	Height   int        `json:"height"`
-	Width    int        `json:"width"`
-	RGBA     color.RGBA `json:"rgba,omitempty"`
-	Hex      string     `json:"hex,omitempty"`
-	Error    string     `json:"error,omitempty"`
-	RawError error      `json:"-"`
+	URL    string     `json:"url"`
+	Height int        `json:"height"`
+	Width  int        `json:"width"`
+	RGBA   color.RGBA `json:"rgba,omitempty"`
+	Hex    string     `json:"hex,omitempty"`
+	*colors.Spaces
+	*placeholder.Hashes
+	Contrast *palette.Contrast `json:"contrast,omitempty"`
+	Palette  *palette.Palette  `json:"palette,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	RawError error             `json:"-"`
+}
+
+// ImageOptions represents the options used to process images.
+type ImageOptions struct {
+	// Palette tells whether to extract the palette of each image.
+	Palette bool
+	// PaletteSize is the maximum number of colors of a palette. Defaults to palette.DefaultSize.
+	PaletteSize int
+	// Dominant holds the options used to find the dominant color. Defaults to the configured ones.
+	Dominant *palette.DominantOptions
 }
 
 // Spoty represents the spoty service.
 type Spoty struct {
-	client *spotify.Client
+	// client is set once authenticated, concurrently with its use by the recorder.
+	clientMu  sync.RWMutex
+	client    *spotify.Client
+	apiClient *http.Client
+	fetcher   Fetcher
+	pool      *workerPool
 
 	auth  spotify.Authenticator
 	state string
 
+	recorder  *recorder
+	interval  time.Duration
+	publisher *messenger.Publisher
+	dominant  palette.DominantOptions
+	colors    *diskcache.Cache
+	covers    *diskcache.Cache
+	// fetched is the last album image downloaded to compute colors.
+	fetched fetchedSource
+
+	analyzeLimits ImageLimits
+	imageLimits   ImageLimits
+	imageHosts    []string
+
 	logger *logger.Logger
 	tracer *tracer.Tracer
 	cache  *cache.Cache
@@ -57,11 +106,16 @@ type Spoty struct {
 
 // New creates a new spoty service.
 func New(
+	lc fx.Lifecycle,
 	cfg *config.Config,
 	logger *logger.Logger,
 	tracer *tracer.Tracer,
 	cache *cache.Cache,
 	health *health.Checks,
+	history *history.History,
+	publisher *messenger.Publisher,
+	fetcher Fetcher,
+	caches *diskcache.Caches,
 ) (*Spoty, error) {
 	if cfg.SpotifyClientID == "" || cfg.SpotifyClientSecret == "" {
 		return nil, errors.New("missing clientID or clientSecret")
@@ -71,37 +125,149 @@ func New(
 		cfg.SpotifyRedirectURI,
 		spotify.ScopeUserReadCurrentlyPlaying,
 		spotify.ScopeUserReadPlaybackState,
+		spotify.ScopeUserReadRecentlyPlayed,
 	)
 
 	auth.SetAuthInfo(cfg.SpotifyClientID, cfg.SpotifyClientSecret)
 
+	dominant := palette.DominantOptions{
+		MinSaturation:    cfg.DominantMinSaturation,
+		MinLightness:     cfg.DominantMinLightness,
+		MaxLightness:     cfg.DominantMaxLightness,
+		SaturationWeight: cfg.DominantSaturationWeight,
+		CenterCrop:       cfg.DominantCenterCrop,
+		Border:           cfg.DominantBorder,
+	}
+	if err := dominant.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid dominant color options: %w", err)
+	}
+
+	if cfg.AnalyzeMaxBytes <= 0 || cfg.AnalyzeMaxDimension <= 0 {
+		return nil, errors.New("analyze limits must be positive")
+	}
+
+	if cfg.ImageMaxBytes <= 0 || cfg.ImageMaxDimension <= 0 {
+		return nil, errors.New("image limits must be positive")
+	}
+
+	if len(cfg.ImageHosts) == 0 {
+		return nil, errors.New("missing image hosts")
+	}
+
+	if cfg.ImageWorkers <= 0 {
+		return nil, errors.New("image workers must be positive")
+	}
+
 	state, err := uuid.NewRandom()
 	if err != nil {
 		return nil, fmt.Errorf("new uuid: %w", err)
 	}
 
 	spoty := Spoty{
-		auth:   auth,
-		state:  state.String(),
-		logger: logger,
-		tracer: tracer,
-		cache:  cache,
-		health: health,
+		auth:  auth,
+		state: state.String(),
+		apiClient: &http.Client{
+			Timeout: _defaultTTL,
+		},
+		fetcher:   fetcher,
+		pool:      newWorkerPool(cfg.ImageWorkers),
+		interval:  cfg.RecorderInterval,
+		publisher: publisher,
+		dominant:  dominant,
+		colors:    caches.Colors,
+		covers:    caches.Covers,
+		analyzeLimits: ImageLimits{
+			MaxBytes:     cfg.AnalyzeMaxBytes,
+			MaxDimension: cfg.AnalyzeMaxDimension,
+			MaxPixels:    cfg.AnalyzeMaxPixels,
+		},
+		imageLimits: ImageLimits{
+			MaxBytes:     cfg.ImageMaxBytes,
+			MaxDimension: cfg.ImageMaxDimension,
+		},
+		imageHosts: cfg.ImageHosts,
+		logger:     logger,
+		tracer:     tracer,
+		cache:      cache,
+		health:     health,
 	}
 
+	spoty.recorder = newRecorder(
+		cfg.RecorderInterval,
+		history,
+		spoty.enrichPlay,
+		spoty.publishPlaybackEvent,
+		newSessionTracker(cfg.SessionGap, spoty.publishSessionEvent),
+		logger,
+	)
 	spoty.health.RegisterChecks(spoty.Check())
 
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go func() {
+				defer close(done)
+				spoty.record(ctx)
+			}()
+
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+
+			defer spoty.pool.Close()
+
+			return spoty.recorder.Flush(stopCtx)
+		},
+	})
+
 	return &spoty, nil
 }
 
+// record periodically polls the playback state so that the recorder observes
+// every playback transition. It blocks until the context is cancelled.
+func (s *Spoty) record(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !s.IsAuth() {
+				continue
+			}
+
+			s.fetchPlaybackState(ctx) //nolint: errcheck
+		}
+	}
+}
+
 // IsAuth returns true if the client was created.
 func (s *Spoty) IsAuth() bool {
-	return s.client != nil
+	return s.spotifyClient() != nil
+}
+
+// spotifyClient returns the spotify client or nil if not authenticated.
+func (s *Spoty) spotifyClient() *spotify.Client {
+	s.clientMu.RLock()
+	defer s.clientMu.RUnlock()
+
+	return s.client
 }
 
 // IsPlaying returns true if the client is currently playing.
 func (s *Spoty) IsPlaying() bool {
-	state, err := s.client.PlayerState()
+	state, err := s.spotifyClient().PlayerState()
 	if err != nil {
 		return false
 	}
@@ -123,124 +289,265 @@ func (s *Spoty) SetupNewClient(r *http.Request) error {
 
 	client := s.auth.NewClient(tok)
 	client.AutoRetry = true
+
+	s.clientMu.Lock()
 	s.client = &client
+	s.clientMu.Unlock()
 
 	return nil
 }
 
-// TrackCurrentlyPlaying returns the currently playing track.
-func (s *Spoty) TrackCurrentlyPlaying(ctx context.Context) (*spotify.FullTrack, error) {
-	ctx, span := s.tracer.Start(ctx, "TrackCurrentlyPlaying")
+// CurrentlyPlaying returns the currently playing item (track or episode).
+func (s *Spoty) CurrentlyPlaying(ctx context.Context) (*Item, error) {
+	ctx, span := s.tracer.Start(ctx, "CurrentlyPlaying")
 	defer span.End()
 
-	const cacheCurrentTrackKey = "current_track"
+	const cacheCurrentItemKey = "current_item"
 
-	cachedTrack, found := s.cache.Get(cacheCurrentTrackKey)
+	cachedItem, found := s.cache.Get(cacheCurrentItemKey)
 	if found {
-		if cachedTrack, ok := cachedTrack.(*spotify.FullTrack); ok {
-			s.logger.Ctx(ctx).Debugw("found cached track", "track", cachedTrack)
+		if cachedItem, ok := cachedItem.(*Item); ok {
+			s.logger.Ctx(ctx).Debugw("found cached item", "item", cachedItem)
 
-			return cachedTrack, nil
+			return cachedItem, nil
 		}
 
-		s.logger.Ctx(ctx).Debugw("failed to parse cached track. retrieving fresh one...", "track", cachedTrack)
+		s.logger.Ctx(ctx).Debugw("failed to parse cached item. retrieving fresh one...", "item", cachedItem)
 	}
 
 	if !s.IsPlaying() {
-		s.logger.ErrorwContext(ctx, "no track currently playing")
+		s.logger.ErrorwContext(ctx, "nothing currently playing")
 
-		return nil, errors.New("no track currently playing")
+		return nil, errors.New("nothing currently playing")
 	}
 
-	playing, err := s.client.PlayerCurrentlyPlaying()
+	var playing currentlyPlaying
+	if err := s.get(ctx, "me/player/currently-playing", _additionalTypes, &playing); err != nil {
+		s.logger.ErrorwContext(ctx, "failed to retrieve currently playing item", "error", err.Error())
+
+		return nil, err
+	}
+
+	item, err := playing.Item()
 	if err != nil {
-		s.logger.ErrorwContext(ctx, "failed to retrieve currently playing track", "error", err.Error())
+		s.logger.ErrorwContext(ctx, "failed to decode currently playing item", "error", err.Error())
 
 		return nil, err
 	}
 
-	s.cache.SetWithTTL(cacheCurrentTrackKey, playing.Item, 0, _defaultTTL)
+	if item == nil {
+		s.logger.ErrorwContext(ctx, "nothing currently playing")
+
+		return nil, errors.New("nothing currently playing")
+	}
+
+	s.cache.SetWithTTL(cacheCurrentItemKey, item, 0, _defaultTTL)
 
-	return playing.Item, nil
+	return item, nil
 }
 
-// TrackImages returns the track images from a track.
-func (s *Spoty) TrackImages(ctx context.Context, track *spotify.FullTrack) ([]Image, error) {
+// TrackImages returns the cover images of an item (album images for a track,
+// episode or show images for an episode) along with their dominant color.
+func (s *Spoty) TrackImages(ctx context.Context, item *Item, opts ImageOptions) ([]Image, error) {
 	ctx, span := s.tracer.Start(ctx, "TrackImages")
 	defer span.End()
 
-	if track == nil {
-		return nil, errors.New("invalid track")
+	if item == nil || item.ID() == "" {
+		return nil, errors.New("invalid item")
 	}
 
-	cacheTrackImagesKey := "track_" + strcase.ToCamel(string(track.ID)) + "_images"
+	return s.processImages(ctx, string(item.Type)+"_"+strcase.ToCamel(string(item.ID())), item.Images(), opts), nil
+}
+
+// processImages downloads images and computes their colors.
+// Results are cached under a key starting with cacheKey, which identifies the images.
+func (s *Spoty) processImages(ctx context.Context, cacheKey string, sources []spotify.Image, opts ImageOptions) []Image {
+	opts = s.withDefaults(opts)
+
+	cacheImagesKey := cacheKey + "_images_" + opts.key()
 
-	cachedImages, found := s.cache.Get(cacheTrackImagesKey)
+	cachedImages, found := s.cache.Get(cacheImagesKey)
 	if found {
 		if cachedImages, ok := cachedImages.([]Image); ok {
 			s.logger.Ctx(ctx).Debugw("found cached images", "images", cachedImages)
 
-			return cachedImages, nil
+			return cachedImages
 		}
 
-		s.logger.Ctx(ctx).Debugw("failed to parse cached images. retrieving fresh ones...", "track", cachedImages)
+		s.logger.Ctx(ctx).Debugw("failed to parse cached images. retrieving fresh ones...", "images", cachedImages)
 	}
 
-	httpClient := &http.Client{
-		Timeout: _defaultTTL,
+	images := make([]Image, len(sources))
+	for i := range sources {
+		images[i] = Image{
+			URL:    sources[i].URL,
+			Height: sources[i].Height,
+			Width:  sources[i].Width,
+		}
 	}
 
-	var wg sync.WaitGroup
+	// The colors of the sizes of an image are the same: they are computed once, from the
+	// smallest adequate size, and shared. The other sizes are only tried if it fails.
+	// Colors persisted for any of the sizes are used before downloading anything.
+	order := colorSources(sources)
+	colored := s.persistedColors(ctx, sources, order, opts)
 
-	var images []Image
-	for i := range track.Album.Images {
-		albumImage := &track.Album.Images[i]
+	var err error
+	for _, i := range order {
+		if colored != nil {
+			break
+		}
 
-		wg.Add(1)
-		go func(albumImage *spotify.Image) {
-			img := Image{
-				URL:    albumImage.URL,
-				Height: albumImage.Height,
-				Width:  albumImage.Width,
-			}
+		url := sources[i].URL
 
-			defer func() {
-				images = append(images, img)
+		if poolErr := s.pool.Do(ctx, func() {
+			colored, err = s.colorImage(ctx, url, opts)
+		}); poolErr != nil {
+			err = &imageError{reason: "could not schedule album image", err: poolErr}
 
-				if img.Error != "" {
-					s.logger.WarnwContext(ctx, img.Error, "error", img.RawError.Error(), "image", img)
-				}
+			break
+		}
 
-				wg.Done()
-			}()
+		if err == nil {
+			s.persistColors(ctx, url, opts, colored)
 
-			resp, err := httpClient.Get(img.URL)
-			if err != nil {
-				img.Error = "could not retrieve album image"
-				img.RawError = err
+			break
+		}
 
-				return
-			}
-			defer resp.Body.Close() //nolint: errcheck
+		s.logger.WarnwContext(ctx, "failed to process album image", "error", err.Error(), "url", url)
+	}
 
-			processedImg, _, err := image.Decode(resp.Body)
-			if err != nil {
-				img.Error = "could not process album image"
-				img.RawError = err
+	for i := range images {
+		if colored != nil {
+			images[i].setColors(colored)
 
-				return
-			}
+			continue
+		}
 
-			img.RGBA = dominantcolor.Find(processedImg)
-			img.Hex = dominantcolor.Hex(img.RGBA)
-		}(albumImage)
+		reason := "could not process album image"
+
+		var iErr *imageError
+		if errors.As(err, &iErr) {
+			reason = iErr.reason
+		}
+
+		images[i].Error = reason
+		images[i].RawError = err
 	}
 
-	wg.Wait()
+	s.cache.SetWithTTL(cacheImagesKey, images, 0, _defaultTTL)
+
+	return images
+}
+
+// setColors copies the colors of another image.
+func (img *Image) setColors(from *Image) {
+	img.RGBA = from.RGBA
+	img.Hex = from.Hex
+	img.Spaces = from.Spaces
+	img.Contrast = from.Contrast
+	img.Hashes = from.Hashes
+	img.Palette = from.Palette
+}
+
+// key returns a key identifying the options. They must have their defaults set.
+func (o ImageOptions) key() string {
+	key := o.Dominant.Key()
+	if o.Palette {
+		key += fmt.Sprintf("_palette_%d", o.PaletteSize)
+	}
+
+	return key
+}
+
+// withDefaults returns the options with the defaults of unset ones.
+func (s *Spoty) withDefaults(opts ImageOptions) ImageOptions {
+	if opts.PaletteSize <= 0 {
+		opts.PaletteSize = palette.DefaultSize
+	}
+
+	if opts.Dominant == nil {
+		dominant := s.dominant
+		opts.Dominant = &dominant
+	}
+
+	return opts
+}
+
+// colorize computes the colors and placeholders of a decoded image. The options must have their defaults set.
+func colorize(img *Image, decoded image.Image, opts ImageOptions) {
+	img.RGBA = palette.Dominant(decoded, *opts.Dominant)
+	img.Hex = dominantcolor.Hex(img.RGBA)
+	img.Spaces = colors.Convert(img.RGBA)
+	img.Contrast = palette.NewContrast(img.RGBA)
+	img.Hashes = placeholder.New(decoded)
+
+	if opts.Palette {
+		img.Palette = palette.Extract(decoded, opts.PaletteSize)
+	}
+}
+
+// DominantOptions returns the configured options used to find the dominant color of images.
+func (s *Spoty) DominantOptions() palette.DominantOptions {
+	return s.dominant
+}
+
+// get performs an authenticated GET request against the spotify web API.
+// It is used instead of the spotify client, which ignores the request contexts,
+// and for endpoints or parameters the spotify client does not support.
+// The result is left untouched if spotify responds with no content.
+func (s *Spoty) get(ctx context.Context, endpoint string, query url.Values, result any) error {
+	tok, err := s.spotifyClient().Token()
+	if err != nil {
+		return fmt.Errorf("token: %w", err)
+	}
+
+	u := _spotifyAPIBaseURL + endpoint
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+
+	tok.SetAuthHeader(req)
+
+	resp, err := s.apiClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close() //nolint: errcheck
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent:
+		return nil
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("spotify: %w", apiError(resp))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return nil
+}
+
+// apiError decodes the error of a failed response of the spotify web API.
+func apiError(resp *http.Response) spotify.Error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10)) //nolint: errcheck
+
+	var e struct {
+		Error spotify.Error `json:"error"`
+	}
+	if err := json.Unmarshal(body, &e); err != nil || e.Error.Message == "" {
+		e.Error.Message = fmt.Sprintf("HTTP %d: %s", resp.StatusCode, body)
+	}
 
-	s.cache.SetWithTTL(cacheTrackImagesKey, images, 0, _defaultTTL)
+	e.Error.Status = resp.StatusCode
 
-	return images, nil
+	return e.Error
 }
 
 // Check checks if the spoty service is authenticated.
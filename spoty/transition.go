@@ -0,0 +1,79 @@
+package spoty
+
+import (
+	"time"
+
+	"github.com/mgjules/spoty/history"
+)
+
+const (
+	// _completionMargin is how close to the end a play must get to be considered completed.
+	_completionMargin = 10 * time.Second
+	// _completionRatio is the fraction of the duration a play must reach to be considered completed.
+	_completionRatio = 0.95
+	// _earlySkipThreshold is the position before which a skip is considered early.
+	_earlySkipThreshold = 30 * time.Second
+)
+
+// transition represents the change from one observed playback state to the next.
+type transition struct {
+	// last is the last state observed for the finished play.
+	last *PlaybackState
+	// lastAt is when the last state was observed.
+	lastAt time.Time
+	// next is the state that ended the play. It is nil if playback stopped.
+	next *PlaybackState
+	// at is when the next state was observed.
+	at time.Time
+}
+
+// tail estimates how long the finished item kept playing after its last observation.
+func (t *transition) tail(durationMs int64) time.Duration {
+	if !t.last.IsPlaying {
+		return 0
+	}
+
+	elapsed := t.at.Sub(t.lastAt)
+	if t.next != nil {
+		if !t.next.IsPlaying {
+			return 0
+		}
+
+		// The finished item played until the next one started.
+		elapsed -= time.Duration(t.next.ProgressMs) * time.Millisecond
+	}
+
+	remaining := durationMs - int64(t.last.ProgressMs)
+
+	return time.Duration(clamp(elapsed.Milliseconds(), 0, remaining)) * time.Millisecond
+}
+
+// classify returns the outcome of a play that reached the given final position.
+// A play ended by a replay of its item is classified like any other: the replay is flagged instead.
+func (t *transition) classify(durationMs int64, position time.Duration) history.Outcome {
+	duration := time.Duration(durationMs) * time.Millisecond
+	if duration <= 0 {
+		return history.OutcomeCompleted
+	}
+
+	if position >= duration-_completionMargin || float64(position) >= float64(duration)*_completionRatio {
+		return history.OutcomeCompleted
+	}
+
+	return skipOutcome(duration, position)
+}
+
+// skipOutcome classifies a skip at the given position as early or late.
+// A skip is early before 30s, or before the half of a shorter item. An unknown duration is zero.
+func skipOutcome(duration, position time.Duration) history.Outcome {
+	early := _earlySkipThreshold
+	if half := duration / 2; duration > 0 && half < early {
+		early = half
+	}
+
+	if position < early {
+		return history.OutcomeSkippedEarly
+	}
+
+	return history.OutcomeSkippedLate
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mgjules/spoty/config"
+	"github.com/mgjules/spoty/health"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/fx"
+)
+
+const _openTimeout = time.Second
+
+// Module exported for initialising a new Store.
+var Module = fx.Options(
+	fx.Provide(New),
+)
+
+// Store is a simple wrapper around bolt.DB.
+type Store struct {
+	*bolt.DB
+
+	health *health.Checks
+}
+
+// New opens and returns a new Store.
+func New(lc fx.Lifecycle, cfg *config.Config, health *health.Checks) (*Store, error) {
+	db, err := bolt.Open(cfg.DatabasePath, 0o600, &bolt.Options{Timeout: _openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(_ context.Context) error {
+			return db.Close()
+		},
+	})
+
+	s := Store{
+		DB:     db,
+		health: health,
+	}
+
+	s.health.RegisterChecks(s.Check())
+
+	return &s, nil
+}
+
+// Check is used to perform healthcheck.
+func (s *Store) Check() health.Check {
+	//nolint:revive
+	return health.Check{
+		Name:          "store",
+		RefreshPeriod: 10 * time.Second,
+		InitialDelay:  10 * time.Second,
+		Timeout:       5 * time.Second,
+		Check: func(_ context.Context) error {
+			return s.View(func(_ *bolt.Tx) error {
+				return nil
+			})
+		},
+	}
+}
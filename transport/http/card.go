@@ -0,0 +1,175 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/mgjules/spoty/card"
+	"github.com/mgjules/spoty/cover"
+	"github.com/mgjules/spoty/palette"
+	"github.com/mgjules/spoty/spoty"
+)
+
+const (
+	// _cardContentSecurityPolicy keeps a card from loading anything but its embedded cover
+	// when it is opened directly.
+	_cardContentSecurityPolicy = "default-src 'none'; img-src data:; style-src 'unsafe-inline'"
+	// _cardCoverQuality is the quality of the covers embedded in cards.
+	_cardCoverQuality = 80
+	// _pngCardTTL is the lifetime of the cached PNG cards.
+	_pngCardTTL = 10 * time.Minute
+	// _pngCardCacheBytes is the maximum size of the cached PNG cards and _pngCardCacheKeys
+	// the number of keys whose access frequency is tracked (ten times the expected number of cards).
+	_pngCardCacheBytes = 8 << 20
+	_pngCardCacheKeys  = 1000
+)
+
+// _cardMaxAges are the lifetimes of the cards of each state.
+// Image proxies (e.g. the one of GitHub) may serve a stale card while they refresh it.
+var _cardMaxAges = map[card.State]time.Duration{
+	card.StatePlaying:    30 * time.Second,
+	card.StatePaused:     time.Minute,
+	card.StateLastPlayed: 5 * time.Minute,
+	card.StateIdle:       5 * time.Minute,
+}
+
+// nowPlayingCard returns the card of the current playing item or, when nothing is playing,
+// of the last played track, embedding a cover of the given size. The gradient of raster cards
+// is only computed if asked, as it needs the palette of the cover.
+func (s *Server) nowPlayingCard(ctx context.Context, coverSize int, withGradient bool) *card.Card {
+	var (
+		item   *spoty.Item
+		images []spoty.Image
+		c      = card.Card{State: card.StateIdle}
+	)
+
+	state, err := s.spoty.PlaybackState(ctx)
+	if err == nil && state.Item != nil {
+		item, images = state.Item, state.Images
+
+		c.State = card.StatePaused
+		if state.IsPlaying {
+			c.State = card.StatePlaying
+		}
+
+		c.ProgressMs = state.ProgressMs
+		c.DurationMs = item.DurationMs()
+	} else {
+		recent, err := s.spoty.RecentlyPlayed(ctx, spoty.RecentOptions{Limit: 1, Colors: true})
+		if err != nil {
+			s.logger.WarnwContext(ctx, "failed to retrieve recently played tracks", "error", err.Error())
+		}
+
+		if err == nil && len(recent.Items) > 0 {
+			last := &recent.Items[0]
+			item, images = &spoty.Item{Type: spoty.ItemTypeTrack, Track: last.Track}, last.Images
+
+			c.State = card.StateLastPlayed
+			c.PlayedAt = last.PlayedAt
+		}
+	}
+
+	if item == nil {
+		return &c
+	}
+
+	c.Title = item.Name()
+	c.Artists = item.Artists()
+	c.Collection = item.Collection()
+
+	if withGradient {
+		paletted, err := s.spoty.TrackImages(ctx, item, spoty.ImageOptions{Palette: true})
+		if err != nil {
+			s.logger.WarnwContext(ctx, "failed to retrieve track images", "error", err.Error())
+		} else {
+			images = paletted
+		}
+	}
+
+	if img := themeImage(images); img != nil {
+		c.Color = &img.RGBA
+
+		if withGradient {
+			c.Gradient = cardGradient(img)
+		}
+	}
+
+	thumbnail, err := s.spoty.Cover(ctx, item, cover.Options{
+		Size:    coverSize,
+		Format:  cover.FormatJPEG,
+		Quality: _cardCoverQuality,
+	})
+	if err != nil {
+		s.logger.WarnwContext(ctx, "failed to retrieve card cover", "error", err.Error())
+	} else {
+		c.Cover, c.CoverType = thumbnail.Data, thumbnail.ContentType
+	}
+
+	return &c
+}
+
+// cardGradient returns the background gradient of a raster card: the dominant color of a cover
+// followed by one of its dark swatches or, failing that, its muted one.
+func cardGradient(img *spoty.Image) []color.RGBA {
+	stops := []color.RGBA{img.RGBA}
+	if img.Palette == nil {
+		return stops
+	}
+
+	swatches := img.Palette.Swatches
+	for _, swatch := range []*palette.Color{swatches.DarkVibrant, swatches.DarkMuted, swatches.Muted} {
+		if swatch != nil && swatch.RGBA != img.RGBA {
+			return append(stops, swatch.RGBA)
+		}
+	}
+
+	return stops
+}
+
+// cardCacheControl returns the Cache-Control header of a card.
+func cardCacheControl(state card.State) string {
+	maxAge := int(_cardMaxAges[state].Seconds())
+
+	return fmt.Sprintf("public, max-age=%d, s-maxage=%d, stale-while-revalidate=%d", maxAge, maxAge, maxAge)
+}
+
+// pngCard represents a PNG card along with its strong ETag.
+type pngCard struct {
+	Data []byte
+	ETag string
+}
+
+// renderPNGCard draws a card as a PNG image on the image workers.
+// Cards are cached by what they show, so that a card is only drawn once until it changes.
+func (s *Server) renderPNGCard(ctx context.Context, c *card.Card, preset card.Preset, now time.Time) (*pngCard, error) {
+	key := "card_png_" + c.PNGKey(preset, now)
+
+	if cached, found := s.pngCards.Get(key); found {
+		if cached, ok := cached.(*pngCard); ok {
+			return cached, nil
+		}
+	}
+
+	var (
+		buf bytes.Buffer
+		err error
+	)
+
+	if poolErr := s.spoty.Render(ctx, func() {
+		err = c.WritePNG(&buf, s.fonts, preset, now)
+	}); poolErr != nil {
+		return nil, fmt.Errorf("schedule card: %w", poolErr)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := &pngCard{Data: buf.Bytes(), ETag: cover.ETag(buf.Bytes())}
+	s.pngCards.SetWithTTL(key, rendered, int64(len(rendered.Data)), _pngCardTTL)
+
+	return rendered, nil
+}
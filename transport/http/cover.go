@@ -0,0 +1,85 @@
+package http
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgjules/spoty/cover"
+	"github.com/mgjules/spoty/dither"
+)
+
+// _coverCacheControl lets clients reuse a cover briefly and revalidate it with its ETag afterwards,
+// since the current cover changes with the playing item.
+const _coverCacheControl = "private, max-age=5, must-revalidate"
+
+type coverQuery struct {
+	Size    int    `form:"size"`
+	Format  string `form:"format"`
+	Fit     string `form:"fit"`
+	Quality int    `form:"quality"`
+}
+
+// options returns the valid cover options set in the query.
+func (q *coverQuery) options() (cover.Options, error) {
+	opts := cover.Options{
+		Size:    q.Size,
+		Format:  cover.Format(q.Format),
+		Fit:     cover.Fit(q.Fit),
+		Quality: q.Quality,
+	}.WithDefaults()
+
+	return opts, opts.Validate()
+}
+
+type bitmapQuery struct {
+	Width   int    `form:"width"`
+	Height  int    `form:"height"`
+	Fit     string `form:"fit"`
+	Palette string `form:"palette"`
+	Dither  string `form:"dither"`
+	Format  string `form:"format"`
+}
+
+// options returns the valid bitmap options set in the query.
+func (q *bitmapQuery) options() (cover.BitmapOptions, error) {
+	opts := cover.BitmapOptions{
+		Width:  q.Width,
+		Height: q.Height,
+		Fit:    cover.Fit(q.Fit),
+		Dither: dither.Algorithm(q.Dither),
+		Format: cover.BitmapFormat(q.Format),
+	}
+
+	if q.Palette != "" {
+		palette, err := dither.ParsePalette(q.Palette)
+		if err != nil {
+			return cover.BitmapOptions{}, err
+		}
+
+		opts.Palette = palette
+	}
+
+	opts = opts.WithDefaults()
+
+	return opts, opts.Validate()
+}
+
+// writeCoverHeaders sets the headers describing a cover.
+// Its dimensions are exposed for raw formats, which carry none.
+func writeCoverHeaders(c *gin.Context, img *cover.Image, etag string) {
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", _coverCacheControl)
+	c.Header("X-Image-Width", strconv.Itoa(img.Width))
+	c.Header("X-Image-Height", strconv.Itoa(img.Height))
+}
+
+// writeBitmapHeaders sets the headers describing a bitmap: those of a cover along with its palette,
+// as comma separated hex colors in index order, and the number of bits of each packed index.
+func writeBitmapHeaders(c *gin.Context, img *cover.Image, etag string, opts cover.BitmapOptions) {
+	writeCoverHeaders(c, img, etag)
+	c.Header("X-Palette", dither.PaletteKey(opts.Palette))
+
+	if opts.Format == cover.BitmapRaw {
+		c.Header("X-Bits-Per-Pixel", strconv.Itoa(dither.BitsPerPixel(len(opts.Palette))))
+	}
+}
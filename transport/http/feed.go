@@ -0,0 +1,177 @@
+package http
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgjules/spoty/feed"
+	"github.com/mgjules/spoty/history"
+)
+
+// _feedSize is the number of plays in a feed.
+const _feedSize = 50
+
+// serveRecentFeed serves the feed of the most recent plays with the given writer.
+// It supports conditional requests through If-None-Match and If-Modified-Since.
+func (s *Server) serveRecentFeed(c *gin.Context, contentType string, write func(f *feed.Feed, w io.Writer) error) {
+	ctx := c.Request.Context()
+
+	page, err := s.history.List(ctx, history.Query{Limit: _feedSize})
+	if err != nil {
+		rErr := NewError(
+			"failed-retrieve-history",
+			"Could not retrieve listening history.",
+			http.StatusInternalServerError,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to retrieve listening history", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusInternalServerError, rErr)
+
+		return
+	}
+
+	link := requestURL(c)
+	etag := feedETag(contentType, link, page.Plays)
+
+	var lastModified time.Time
+	if len(page.Plays) > 0 {
+		lastModified = page.Plays[0].EndedAt.UTC().Truncate(time.Second)
+		c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+
+	c.Header("ETag", etag)
+
+	if notModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Status(http.StatusOK)
+
+	if err := write(newRecentFeed(link, lastModified, page.Plays), c.Writer); err != nil {
+		s.logger.ErrorwContext(ctx, "failed to write feed", "error", err.Error())
+		c.Abort()
+	}
+}
+
+// newRecentFeed returns the feed of the given plays.
+func newRecentFeed(link string, updated time.Time, plays []history.Play) *feed.Feed {
+	f := feed.Feed{
+		ID:       "urn:spoty:feeds:recent",
+		Title:    "Spoty - Recent listens",
+		Subtitle: "Recently played tracks and episodes on Spotify.",
+		Link:     link,
+		Updated:  updated,
+		Entries:  make([]feed.Entry, 0, len(plays)),
+	}
+
+	if updated.IsZero() {
+		f.Updated = time.Now()
+	}
+
+	for i := range plays {
+		f.Entries = append(f.Entries, newFeedEntry(&plays[i]))
+	}
+
+	return &f
+}
+
+func newFeedEntry(play *history.Play) feed.Entry {
+	artists := make([]string, 0, len(play.Artists))
+	for _, artist := range play.Artists {
+		artists = append(artists, artist.Name)
+	}
+
+	entry := feed.Entry{
+		ID:        "urn:spoty:play:" + play.ID,
+		Title:     play.Name,
+		Author:    strings.Join(artists, ", "),
+		Published: play.StartedAt,
+		Updated:   play.EndedAt,
+		Color:     play.Color,
+	}
+
+	summary := entry.Author
+	if play.Album.Name != "" {
+		summary += " - " + play.Album.Name
+	}
+
+	if play.Color != "" {
+		summary += " (" + play.Color + ")"
+	}
+
+	entry.Summary = summary
+
+	// Imported plays may not have a spotify ID.
+	if !history.IsDerivedID(play.ItemID) {
+		entry.Link = "https://open.spotify.com/" + play.ItemType + "/" + play.ItemID
+	}
+
+	if play.ImageURL != "" {
+		entry.Image = &feed.Enclosure{
+			URL:  play.ImageURL,
+			Type: "image/jpeg",
+		}
+	}
+
+	return entry
+}
+
+// feedETag returns a strong ETag identifying the content of a feed.
+func feedETag(contentType, link string, plays []history.Play) string {
+	h := fnv.New64a()
+	h.Write([]byte(contentType + "\n" + link)) //nolint: errcheck
+
+	for i := range plays {
+		h.Write([]byte("\n" + plays[i].ID + string(plays[i].Outcome) + plays[i].Color)) //nolint: errcheck
+	}
+
+	return fmt.Sprintf(`"%016x"`, h.Sum64())
+}
+
+// notModified tells whether the client already has the current representation.
+// If-None-Match takes precedence over If-Modified-Since (RFC 7232, section 6).
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+
+		return err == nil && !lastModified.After(t)
+	}
+
+	return false
+}
+
+// requestURL returns the absolute URL of the request, without its query.
+func requestURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+
+	return scheme + "://" + c.Request.Host + c.Request.URL.Path
+}
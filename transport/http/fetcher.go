@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/mgjules/spoty/config"
+	"github.com/mgjules/spoty/spoty"
+	"github.com/mgjules/spoty/tracer"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// _maxRedirects is the maximum number of redirects followed by the Fetcher.
+const _maxRedirects = 10
+
+// Fetcher downloads album images with a Client of its own, whose redirects cannot escape
+// the allowed image hosts.
+type Fetcher struct {
+	client *Client
+	tracer *tracer.Tracer
+}
+
+// NewFetcher creates a new Fetcher for the spoty service.
+func NewFetcher(cfg *config.Config, tracer *tracer.Tracer) spoty.Fetcher {
+	client := NewClient(cfg)
+	client.SetRedirectPolicy(resty.RedirectPolicyFunc(func(req *http.Request, via []*http.Request) error {
+		if len(via) >= _maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", _maxRedirects)
+		}
+
+		// Redirects must not escape the allowed image hosts.
+		if req.URL.Scheme != "https" || (req.URL.Port() != "" && req.URL.Port() != "443") ||
+			!spoty.AllowedHost(cfg.ImageHosts, req.URL.Hostname()) {
+			return fmt.Errorf("redirect to %s: %w", req.URL.Redacted(), spoty.ErrImageHost)
+		}
+
+		return nil
+	}))
+
+	return &Fetcher{client: client, tracer: tracer}
+}
+
+// Fetch performs a traced GET request and returns the body of a successful response.
+// The body must be closed.
+func (f *Fetcher) Fetch(ctx context.Context, url string) (io.ReadCloser, error) {
+	ctx, span := f.tracer.Start(ctx, "HTTP GET",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPMethodKey.String(http.MethodGet),
+			semconv.HTTPURLKey.String(url),
+		),
+	)
+	defer span.End()
+
+	resp, err := f.client.R().SetContext(ctx).SetDoNotParseResponse(true).Get(url)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, fmt.Errorf("get: %w", err)
+	}
+
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode()))
+
+	if resp.StatusCode() != http.StatusOK {
+		resp.RawBody().Close() //nolint: errcheck
+
+		err := fmt.Errorf("unexpected status: %s", resp.Status())
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	return resp.RawBody(), nil
+}
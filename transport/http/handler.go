@@ -1,13 +1,26 @@
 package http
 
 import (
+	"bytes"
+	"errors"
 	"net/http"
+	"time"
+	_ "time/tzdata" // time zones for distributions in minimal images.
 
 	ahealth "github.com/alexliesenfeld/health"
 	"github.com/gin-gonic/gin"
+	"github.com/iancoleman/strcase"
+	"github.com/mgjules/spoty/card"
+	"github.com/mgjules/spoty/cover"
 	"github.com/mgjules/spoty/docs"
+	"github.com/mgjules/spoty/feed"
+	"github.com/mgjules/spoty/history"
+	"github.com/mgjules/spoty/palette"
+	"github.com/mgjules/spoty/scheme"
+	"github.com/mgjules/spoty/spoty"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"github.com/swaggo/gin-swagger/swaggerFiles"
+	"github.com/zmb3/spotify"
 )
 
 // Success defines the structure for a successful response.
@@ -56,68 +69,517 @@ func (Server) handleSwagger() gin.HandlerFunc {
 }
 
 // handleCurrentTrack godoc
-// @Summary Current Playing Track
-// @Description returns information about the current playing track
+// @Summary Current Playing Item
+// @Description returns information about the current playing item (track or podcast episode)
 // @Tags spoty
 // @Produce json
-// @Success 200 {object} spotify.FullTrack "returns full track information"
+// @Success 200 {object} spoty.CurrentItem "returns full track information, or the episode information along with its type"
 // @Failure 401 {object} http.Error "not authenticated"
-// @Failure 404 {object} http.Error "no current playing track found"
+// @Failure 404 {object} http.Error "no current playing item found"
 // @Router /api/current [get]
 func (s *Server) handleCurrentTrack(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	track, err := s.spoty.TrackCurrentlyPlaying(ctx)
+	item, err := s.spoty.CurrentlyPlaying(ctx)
 	if err != nil {
 		rErr := NewError(
 			"no-playing-track",
-			"No track playing currently.",
+			"Nothing playing currently.",
 			http.StatusNotFound,
 			err.Error(),
 			c.Request.URL.String(),
 			nil,
 		)
 
-		s.logger.ErrorwContext(ctx, "failed to retrieve current playing track", "error", rErr.Error())
+		s.logger.ErrorwContext(ctx, "failed to retrieve current playing item", "error", rErr.Error())
 		c.AbortWithStatusJSON(http.StatusNotFound, rErr)
 
 		return
 	}
 
-	c.JSON(http.StatusOK, track)
+	c.JSON(http.StatusOK, item.Current())
+}
+
+type imagesQuery struct {
+	Palette     bool `form:"palette"`
+	PaletteSize int  `form:"palette_size" binding:"omitempty,min=1,max=32"`
+
+	MinSaturation    *float64 `form:"min_saturation" binding:"omitempty,min=0,max=1"`
+	MinLightness     *float64 `form:"min_lightness" binding:"omitempty,min=0,max=1"`
+	MaxLightness     *float64 `form:"max_lightness" binding:"omitempty,min=0,max=1"`
+	SaturationWeight *float64 `form:"saturation_weight" binding:"omitempty,min=0,max=100"`
+	CenterCrop       *float64 `form:"center_crop" binding:"omitempty,gt=0,max=1"`
+	Border           *float64 `form:"border" binding:"omitempty,min=0,max=0.45"`
+}
+
+// dominantOptions returns the given defaults overridden by the options set in the query.
+// It returns nil if the query sets none.
+func (q *imagesQuery) dominantOptions(defaults palette.DominantOptions) *palette.DominantOptions {
+	opts := defaults
+	set := false
+
+	for _, o := range []struct {
+		value *float64
+		field *float64
+	}{
+		{q.MinSaturation, &opts.MinSaturation},
+		{q.MinLightness, &opts.MinLightness},
+		{q.MaxLightness, &opts.MaxLightness},
+		{q.SaturationWeight, &opts.SaturationWeight},
+		{q.CenterCrop, &opts.CenterCrop},
+		{q.Border, &opts.Border},
+	} {
+		if o.value != nil {
+			*o.field = *o.value
+			set = true
+		}
+	}
+
+	if !set {
+		return nil
+	}
+
+	return &opts
+}
+
+// bindImageOptions binds the image options from the query parameters.
+// It aborts the request and returns false if they are invalid.
+func (s *Server) bindImageOptions(c *gin.Context) (spoty.ImageOptions, bool) {
+	var query imagesQuery
+
+	err := c.ShouldBindQuery(&query)
+
+	dominant := query.dominantOptions(s.spoty.DominantOptions())
+	if err == nil && dominant != nil {
+		err = dominant.Validate()
+	}
+
+	if err != nil {
+		rErr := NewError(
+			"invalid-query-parameters",
+			"Invalid query parameters.",
+			http.StatusBadRequest,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(c.Request.Context(), "failed to parse query parameters", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusBadRequest, rErr)
+
+		return spoty.ImageOptions{}, false
+	}
+
+	return spoty.ImageOptions{
+		Palette:     query.Palette,
+		PaletteSize: query.PaletteSize,
+		Dominant:    dominant,
+	}, true
 }
 
 // handleCurrentTrackImages godoc
-// @Summary Album Images of Current Playing Track
-// @Description returns the album images of the current playing track
+// @Summary Cover Images of Current Playing Item
+// @Description returns the album images of the current playing track or the cover images of the current playing episode
 // @Tags spoty
 // @Produce json
-// @Success 200 {array} spoty.Image "returns album images"
+// @Param palette query bool false "whether to include the palette (main colors and swatches) of each image"
+// @Param palette_size query int false "maximum number of colors of a palette (1-32)" default(8)
+// @Param min_saturation query number false "ignore colors whose saturation is below it when finding the dominant color (0-1)"
+// @Param min_lightness query number false "ignore colors whose lightness is below it when finding the dominant color (0-1)"
+// @Param max_lightness query number false "ignore colors whose lightness is above it when finding the dominant color (0-1)"
+// @Param saturation_weight query number false "favour saturated colors when finding the dominant color (0 disables it)"
+// @Param center_crop query number false "share of each dimension of the cover kept around its center when finding the dominant color (0-1]"
+// @Param border query number false "share of each dimension of the cover ignored at each border when finding the dominant color (0-0.45)"
+// @Success 200 {array} spoty.Image "returns cover images"
+// @Failure 400 {object} http.Error "invalid query parameters"
 // @Failure 401 {object} http.Error "not authenticated"
-// @Failure 404 {object} http.Error "no current playing track found"
+// @Failure 404 {object} http.Error "no current playing item found"
 // @Failure 500 {object} http.Error "album images could not be processed"
 // @Router /api/current/images [get]
 func (s *Server) handleCurrentTrackImages(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	track, err := s.spoty.TrackCurrentlyPlaying(ctx)
+	opts, ok := s.bindImageOptions(c)
+	if !ok {
+		return
+	}
+
+	item, err := s.spoty.CurrentlyPlaying(ctx)
+	if err != nil {
+		rErr := NewError(
+			"no-playing-track",
+			"Nothing playing currently.",
+			http.StatusNotFound,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to retrieve current playing item", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusNotFound, rErr)
+
+		return
+	}
+
+	images, err := s.spoty.TrackImages(ctx, item, opts)
+	if err != nil {
+		rErr := NewError(
+			"failed-retrieve-track-images",
+			"Could not retrieve track images.",
+			http.StatusInternalServerError,
+			err.Error(),
+			c.Request.URL.String(),
+			map[string]any{
+				"item": item,
+			},
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to retrieve track images", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusInternalServerError, rErr)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, images)
+}
+
+// handleCurrentTheme godoc
+// @Summary Theme of Current Playing Item
+// @Description returns a stylesheet declaring the colors of the current playing item's cover as CSS custom properties (--spoty-primary, --spoty-on-primary, --spoty-vibrant, ...); supports conditional requests
+// @Tags spoty
+// @Produce text/css
+// @Success 200 {string} string "returns the stylesheet"
+// @Success 304 {string} string "stylesheet not modified"
+// @Failure 401 {object} http.Error "not authenticated"
+// @Failure 404 {object} http.Error "no current playing item found"
+// @Failure 500 {object} http.Error "cover images could not be processed"
+// @Router /api/current/theme.css [get]
+func (s *Server) handleCurrentTheme(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	item, err := s.spoty.CurrentlyPlaying(ctx)
+	if err != nil {
+		rErr := NewError(
+			"no-playing-track",
+			"Nothing playing currently.",
+			http.StatusNotFound,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to retrieve current playing item", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusNotFound, rErr)
+
+		return
+	}
+
+	images, err := s.spoty.TrackImages(ctx, item, spoty.ImageOptions{Palette: true})
+	if err == nil && themeImage(images) == nil {
+		err = errors.New("no processable cover image")
+	}
+
+	if err != nil {
+		rErr := NewError(
+			"failed-retrieve-track-images",
+			"Could not retrieve track images.",
+			http.StatusInternalServerError,
+			err.Error(),
+			c.Request.URL.String(),
+			map[string]any{
+				"item": item,
+			},
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to retrieve track images", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusInternalServerError, rErr)
+
+		return
+	}
+
+	css := newThemeCSS(item, themeImage(images))
+	etag := themeETag(css)
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", _themeCacheControl)
+
+	if notModified(c.Request, etag, time.Time{}) {
+		c.Status(http.StatusNotModified)
+
+		return
+	}
+
+	c.Data(http.StatusOK, "text/css; charset=utf-8", css)
+}
+
+// handleCurrentScheme godoc
+// @Summary Color Scheme of Current Playing Item
+// @Description returns a Material-style color scheme (tonal palettes and light/dark roles) generated from the dominant color of the current playing item's cover
+// @Tags spoty
+// @Produce json
+// @Success 200 {object} scheme.Scheme "returns the color scheme"
+// @Failure 401 {object} http.Error "not authenticated"
+// @Failure 404 {object} http.Error "no current playing item found"
+// @Failure 500 {object} http.Error "cover images could not be processed"
+// @Router /api/current/scheme [get]
+func (s *Server) handleCurrentScheme(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	item, err := s.spoty.CurrentlyPlaying(ctx)
+	if err != nil {
+		rErr := NewError(
+			"no-playing-track",
+			"Nothing playing currently.",
+			http.StatusNotFound,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to retrieve current playing item", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusNotFound, rErr)
+
+		return
+	}
+
+	images, err := s.spoty.TrackImages(ctx, item, spoty.ImageOptions{})
+	if err == nil && themeImage(images) == nil {
+		err = errors.New("no processable cover image")
+	}
+
+	if err != nil {
+		rErr := NewError(
+			"failed-retrieve-track-images",
+			"Could not retrieve track images.",
+			http.StatusInternalServerError,
+			err.Error(),
+			c.Request.URL.String(),
+			map[string]any{
+				"item": item,
+			},
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to retrieve track images", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusInternalServerError, rErr)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, scheme.New(themeImage(images).RGBA))
+}
+
+// handleCurrentCover godoc
+// @Summary Cover of Current Playing Item
+// @Description returns the cover of the current playing item resized for constrained clients; the webp format falls back to png and the rgb565 format is raw big-endian pixels, row by row, whose dimensions are given by the X-Image-Width and X-Image-Height headers; supports conditional requests
+// @Tags spoty
+// @Produce image/jpeg,image/png,application/octet-stream
+// @Param size query int false "width and height of the cover (1-1024)" default(300)
+// @Param format query string false "encoding of the cover" Enums(jpeg, png, webp, rgb565) default(jpeg)
+// @Param fit query string false "how the cover is fitted to its size: cropped around its center, scaled to fit or stretched" Enums(cover, contain, fill) default(cover)
+// @Param quality query int false "quality of jpeg covers (1-100)" default(85)
+// @Success 200 {file} file "returns the cover"
+// @Success 304 {string} string "cover not modified"
+// @Failure 400 {object} http.Error "invalid query parameters"
+// @Failure 401 {object} http.Error "not authenticated"
+// @Failure 404 {object} http.Error "no current playing item or cover found"
+// @Failure 500 {object} http.Error "cover could not be processed"
+// @Router /api/current/cover [get]
+func (s *Server) handleCurrentCover(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var (
+		query coverQuery
+		opts  cover.Options
+	)
+
+	err := c.ShouldBindQuery(&query)
+	if err == nil {
+		opts, err = query.options()
+	}
+
+	if err != nil {
+		rErr := NewError(
+			"invalid-query-parameters",
+			"Invalid query parameters.",
+			http.StatusBadRequest,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to parse query parameters", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusBadRequest, rErr)
+
+		return
+	}
+
+	item, err := s.spoty.CurrentlyPlaying(ctx)
+	if err != nil {
+		rErr := NewError(
+			"no-playing-track",
+			"Nothing playing currently.",
+			http.StatusNotFound,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to retrieve current playing item", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusNotFound, rErr)
+
+		return
+	}
+
+	img, err := s.spoty.Cover(ctx, item, opts)
+	if err != nil {
+		s.abortCoverError(c, err, item)
+
+		return
+	}
+
+	writeCoverHeaders(c, img.Image, img.ETag)
+
+	if notModified(c.Request, img.ETag, time.Time{}) {
+		c.Status(http.StatusNotModified)
+
+		return
+	}
+
+	c.Data(http.StatusOK, img.ContentType, img.Data)
+}
+
+// handleCurrentBitmap godoc
+// @Summary Bitmap of Current Playing Item
+// @Description returns the cover of the current playing item resized and quantized to a palette for e-paper displays and LED matrices; the raw format packs the palette indexes with 1, 2, 4 or 8 bits each (X-Bits-Per-Pixel header), most significant bits first, every row starting on a new byte; the palette is given by the X-Palette header and the dimensions by the X-Image-Width and X-Image-Height headers; supports conditional requests
+// @Tags spoty
+// @Produce image/png,application/octet-stream
+// @Param width query int false "width of the bitmap (1-512)" default(64)
+// @Param height query int false "height of the bitmap (1-512)" default(64)
+// @Param fit query string false "how the cover is fitted to the bitmap: cropped around its center, scaled to fit or stretched" Enums(cover, contain, fill) default(cover)
+// @Param palette query string false "bw (black and white), gray4 (4 grays), bwr (black, white and red) or comma separated hex colors" default(bw)
+// @Param dither query string false "dithering algorithm" Enums(none, floyd-steinberg, atkinson, bayer) default(floyd-steinberg)
+// @Param format query string false "encoding of the bitmap" Enums(png, raw) default(png)
+// @Success 200 {file} file "returns the bitmap"
+// @Success 304 {string} string "bitmap not modified"
+// @Failure 400 {object} http.Error "invalid query parameters"
+// @Failure 401 {object} http.Error "not authenticated"
+// @Failure 404 {object} http.Error "no current playing item or cover found"
+// @Failure 500 {object} http.Error "cover could not be processed"
+// @Router /api/current/bitmap [get]
+func (s *Server) handleCurrentBitmap(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var (
+		query bitmapQuery
+		opts  cover.BitmapOptions
+	)
+
+	err := c.ShouldBindQuery(&query)
+	if err == nil {
+		opts, err = query.options()
+	}
+
+	if err != nil {
+		rErr := NewError(
+			"invalid-query-parameters",
+			"Invalid query parameters.",
+			http.StatusBadRequest,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to parse query parameters", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusBadRequest, rErr)
+
+		return
+	}
+
+	item, err := s.spoty.CurrentlyPlaying(ctx)
 	if err != nil {
 		rErr := NewError(
 			"no-playing-track",
-			"No track playing currently.",
+			"Nothing playing currently.",
 			http.StatusNotFound,
 			err.Error(),
 			c.Request.URL.String(),
 			nil,
 		)
 
-		s.logger.ErrorwContext(ctx, "failed to retrieve current playing track", "error", rErr.Error())
+		s.logger.ErrorwContext(ctx, "failed to retrieve current playing item", "error", rErr.Error())
 		c.AbortWithStatusJSON(http.StatusNotFound, rErr)
 
 		return
 	}
 
-	images, err := s.spoty.TrackImages(ctx, track)
+	img, err := s.spoty.Bitmap(ctx, item, opts)
+	if err != nil {
+		s.abortCoverError(c, err, item)
+
+		return
+	}
+
+	writeBitmapHeaders(c, img.Image, img.ETag, opts)
+
+	if notModified(c.Request, img.ETag, time.Time{}) {
+		c.Status(http.StatusNotModified)
+
+		return
+	}
+
+	c.Data(http.StatusOK, img.ContentType, img.Data)
+}
+
+type catalogURI struct {
+	ID string `uri:"id" binding:"required,alphanum,max=64"`
+}
+
+type colorsRequest struct {
+	Type string   `json:"type" binding:"omitempty,oneof=track album" enums:"track,album" default:"track"`
+	IDs  []string `json:"ids" binding:"required,min=1,max=50,dive,required,alphanum,max=64"`
+}
+
+// handleTrackImages godoc
+// @Summary Cover Images of a Track
+// @Description returns the album images of any track along with their colors
+// @Tags spoty
+// @Produce json
+// @Param id path string true "spotify ID of the track"
+// @Param palette query bool false "whether to include the palette (main colors and swatches) of each image"
+// @Param palette_size query int false "maximum number of colors of a palette (1-32)" default(8)
+// @Param min_saturation query number false "ignore colors whose saturation is below it when finding the dominant color (0-1)"
+// @Param min_lightness query number false "ignore colors whose lightness is below it when finding the dominant color (0-1)"
+// @Param max_lightness query number false "ignore colors whose lightness is above it when finding the dominant color (0-1)"
+// @Param saturation_weight query number false "favour saturated colors when finding the dominant color (0 disables it)"
+// @Param center_crop query number false "share of each dimension of the cover kept around its center when finding the dominant color (0-1]"
+// @Param border query number false "share of each dimension of the cover ignored at each border when finding the dominant color (0-0.45)"
+// @Success 200 {array} spoty.Image "returns cover images"
+// @Failure 400 {object} http.Error "invalid parameters"
+// @Failure 401 {object} http.Error "not authenticated"
+// @Failure 404 {object} http.Error "track not found"
+// @Failure 500 {object} http.Error "track could not be retrieved"
+// @Router /api/tracks/{id}/images [get]
+func (s *Server) handleTrackImages(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	uri, ok := s.bindCatalogURI(c)
+	if !ok {
+		return
+	}
+
+	opts, ok := s.bindImageOptions(c)
+	if !ok {
+		return
+	}
+
+	item, err := s.spoty.Track(ctx, spotify.ID(uri.ID))
+	if err != nil {
+		s.abortCatalogError(c, err, "track")
+
+		return
+	}
+
+	images, err := s.spoty.TrackImages(ctx, item, opts)
 	if err != nil {
 		rErr := NewError(
 			"failed-retrieve-track-images",
@@ -126,7 +588,7 @@ func (s *Server) handleCurrentTrackImages(c *gin.Context) {
 			err.Error(),
 			c.Request.URL.String(),
 			map[string]any{
-				"track": track,
+				"item": item,
 			},
 		)
 
@@ -139,6 +601,854 @@ func (s *Server) handleCurrentTrackImages(c *gin.Context) {
 	c.JSON(http.StatusOK, images)
 }
 
+// handleAlbumImages godoc
+// @Summary Cover Images of an Album
+// @Description returns the images of any album along with their colors
+// @Tags spoty
+// @Produce json
+// @Param id path string true "spotify ID of the album"
+// @Param palette query bool false "whether to include the palette (main colors and swatches) of each image"
+// @Param palette_size query int false "maximum number of colors of a palette (1-32)" default(8)
+// @Param min_saturation query number false "ignore colors whose saturation is below it when finding the dominant color (0-1)"
+// @Param min_lightness query number false "ignore colors whose lightness is below it when finding the dominant color (0-1)"
+// @Param max_lightness query number false "ignore colors whose lightness is above it when finding the dominant color (0-1)"
+// @Param saturation_weight query number false "favour saturated colors when finding the dominant color (0 disables it)"
+// @Param center_crop query number false "share of each dimension of the cover kept around its center when finding the dominant color (0-1]"
+// @Param border query number false "share of each dimension of the cover ignored at each border when finding the dominant color (0-0.45)"
+// @Success 200 {array} spoty.Image "returns cover images"
+// @Failure 400 {object} http.Error "invalid parameters"
+// @Failure 401 {object} http.Error "not authenticated"
+// @Failure 404 {object} http.Error "album not found"
+// @Failure 500 {object} http.Error "album could not be retrieved"
+// @Router /api/albums/{id}/images [get]
+func (s *Server) handleAlbumImages(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	uri, ok := s.bindCatalogURI(c)
+	if !ok {
+		return
+	}
+
+	opts, ok := s.bindImageOptions(c)
+	if !ok {
+		return
+	}
+
+	album, err := s.spoty.Album(ctx, spotify.ID(uri.ID))
+	if err != nil {
+		s.abortCatalogError(c, err, "album")
+
+		return
+	}
+
+	images, err := s.spoty.AlbumImages(ctx, album, opts)
+	if err != nil {
+		rErr := NewError(
+			"failed-retrieve-album-images",
+			"Could not retrieve album images.",
+			http.StatusInternalServerError,
+			err.Error(),
+			c.Request.URL.String(),
+			map[string]any{
+				"album": album.ID,
+			},
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to retrieve album images", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusInternalServerError, rErr)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, images)
+}
+
+// handleBatchColors godoc
+// @Summary Cover Images of Several Tracks or Albums
+// @Description returns the images of up to 50 tracks or albums along with their colors; items that could not be processed have an error instead of images
+// @Tags spoty
+// @Accept json
+// @Produce json
+// @Param request body colorsRequest true "type and spotify IDs of the items"
+// @Param palette query bool false "whether to include the palette (main colors and swatches) of each image"
+// @Param palette_size query int false "maximum number of colors of a palette (1-32)" default(8)
+// @Param min_saturation query number false "ignore colors whose saturation is below it when finding the dominant color (0-1)"
+// @Param min_lightness query number false "ignore colors whose lightness is below it when finding the dominant color (0-1)"
+// @Param max_lightness query number false "ignore colors whose lightness is above it when finding the dominant color (0-1)"
+// @Param saturation_weight query number false "favour saturated colors when finding the dominant color (0 disables it)"
+// @Param center_crop query number false "share of each dimension of the cover kept around its center when finding the dominant color (0-1]"
+// @Param border query number false "share of each dimension of the cover ignored at each border when finding the dominant color (0-0.45)"
+// @Success 200 {array} spoty.ImagesResult "returns the images of each item, in the order of the request"
+// @Failure 400 {object} http.Error "invalid parameters"
+// @Failure 401 {object} http.Error "not authenticated"
+// @Failure 500 {object} http.Error "items could not be processed"
+// @Router /api/colors [post]
+func (s *Server) handleBatchColors(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req colorsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rErr := NewError(
+			"invalid-request-body",
+			"Invalid request body.",
+			http.StatusBadRequest,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to parse request body", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusBadRequest, rErr)
+
+		return
+	}
+
+	opts, ok := s.bindImageOptions(c)
+	if !ok {
+		return
+	}
+
+	typ := spoty.ResourceTypeTrack
+	if req.Type != "" {
+		typ = spoty.ResourceType(req.Type)
+	}
+
+	ids := make([]spotify.ID, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		ids = append(ids, spotify.ID(id))
+	}
+
+	results, err := s.spoty.BatchImages(ctx, typ, ids, opts)
+	if err != nil {
+		rErr := NewError(
+			"failed-process-batch",
+			"Could not process the items.",
+			http.StatusInternalServerError,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to process batch", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusInternalServerError, rErr)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// handleAnalyze godoc
+// @Summary Colors of an Uploaded Image
+// @Description returns the colors of an uploaded JPEG or PNG image, sent either as the "image" field of a multipart form or as the raw request body
+// @Tags spoty
+// @Accept multipart/form-data,image/jpeg,image/png
+// @Produce json
+// @Param image formData file false "image to analyze (multipart requests)"
+// @Param palette query bool false "whether to include the palette (main colors and swatches) of the image"
+// @Param palette_size query int false "maximum number of colors of a palette (1-32)" default(8)
+// @Param min_saturation query number false "ignore colors whose saturation is below it when finding the dominant color (0-1)"
+// @Param min_lightness query number false "ignore colors whose lightness is below it when finding the dominant color (0-1)"
+// @Param max_lightness query number false "ignore colors whose lightness is above it when finding the dominant color (0-1)"
+// @Param saturation_weight query number false "favour saturated colors when finding the dominant color (0 disables it)"
+// @Param center_crop query number false "share of each dimension of the image kept around its center when finding the dominant color (0-1]"
+// @Param border query number false "share of each dimension of the image ignored at each border when finding the dominant color (0-0.45)"
+// @Success 200 {object} spoty.Image "returns the image colors"
+// @Failure 400 {object} http.Error "invalid parameters or image"
+// @Failure 401 {object} http.Error "not authenticated"
+// @Failure 413 {object} http.Error "image too large"
+// @Failure 415 {object} http.Error "unsupported image type"
+// @Router /api/analyze [post]
+func (s *Server) handleAnalyze(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	opts, ok := s.bindImageOptions(c)
+	if !ok {
+		return
+	}
+
+	// An image of the maximum size takes longer to upload than the read timeout of the server.
+	if err := setReadDeadline(ctx, time.Now().Add(_uploadReadTimeout)); err != nil {
+		s.logger.WarnwContext(ctx, "failed to extend the read deadline of the upload", "error", err.Error())
+	}
+
+	src, err := s.uploadedImage(c)
+	if err != nil {
+		rErr := NewError(
+			"invalid-upload",
+			"Invalid upload.",
+			http.StatusBadRequest,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to read upload", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusBadRequest, rErr)
+
+		return
+	}
+
+	img, err := s.spoty.AnalyzeImage(ctx, src, opts)
+	if err != nil {
+		status, typ, title := http.StatusBadRequest, "invalid-image", "Invalid image."
+
+		switch {
+		case errors.Is(err, spoty.ErrImageTooLarge), errors.Is(err, spoty.ErrImageDimensions):
+			status, typ, title = http.StatusRequestEntityTooLarge, "image-too-large", "Image too large."
+		case errors.Is(err, spoty.ErrUnsupportedImage):
+			status, typ, title = http.StatusUnsupportedMediaType, "unsupported-image-type", "Unsupported image type."
+		}
+
+		rErr := NewError(
+			typ,
+			title,
+			status,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to analyze image", "error", rErr.Error())
+		c.AbortWithStatusJSON(status, rErr)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, img)
+}
+
+// bindCatalogURI binds the ID of a catalog item from the path.
+// It aborts the request and returns false if it is invalid.
+func (s *Server) bindCatalogURI(c *gin.Context) (*catalogURI, bool) {
+	var uri catalogURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		rErr := NewError(
+			"invalid-path-parameters",
+			"Invalid path parameters.",
+			http.StatusBadRequest,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(c.Request.Context(), "failed to parse path parameters", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusBadRequest, rErr)
+
+		return nil, false
+	}
+
+	return &uri, true
+}
+
+// abortCatalogError aborts the request after the failed retrieval of a catalog item of the given kind.
+func (s *Server) abortCatalogError(c *gin.Context, err error, kind string) {
+	status, typ, title := http.StatusInternalServerError, "failed-retrieve-"+kind, "Could not retrieve "+kind+"."
+	if errors.Is(err, spoty.ErrNotFound) {
+		status, typ, title = http.StatusNotFound, kind+"-not-found", strcase.ToCamel(kind)+" not found."
+	}
+
+	rErr := NewError(
+		typ,
+		title,
+		status,
+		err.Error(),
+		c.Request.URL.String(),
+		nil,
+	)
+
+	s.logger.ErrorwContext(c.Request.Context(), "failed to retrieve "+kind, "error", rErr.Error())
+	c.AbortWithStatusJSON(status, rErr)
+}
+
+// abortCoverError aborts the request after the failed rendering of the cover of an item.
+func (s *Server) abortCoverError(c *gin.Context, err error, item *spoty.Item) {
+	status, typ, title := http.StatusInternalServerError, "failed-retrieve-cover", "Could not retrieve cover."
+	switch {
+	case errors.Is(err, spoty.ErrNoCover):
+		status, typ, title = http.StatusNotFound, "cover-not-found", "Cover not found."
+	case errors.Is(err, spoty.ErrInvalidOptions):
+		status, typ, title = http.StatusBadRequest, "invalid-query-parameters", "Invalid query parameters."
+	}
+
+	rErr := NewError(
+		typ,
+		title,
+		status,
+		err.Error(),
+		c.Request.URL.String(),
+		map[string]any{
+			"item": item,
+		},
+	)
+
+	s.logger.ErrorwContext(c.Request.Context(), "failed to retrieve cover", "error", rErr.Error())
+	c.AbortWithStatusJSON(status, rErr)
+}
+
+// handleCurrentPlayer godoc
+// @Summary Current Playback State
+// @Description returns the full playback state including the current track, device, context and dominant colors
+// @Tags spoty
+// @Produce json
+// @Success 200 {object} spoty.PlaybackState "returns playback state"
+// @Failure 401 {object} http.Error "not authenticated"
+// @Failure 404 {object} http.Error "no active playback found"
+// @Router /api/player [get]
+func (s *Server) handleCurrentPlayer(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	state, err := s.spoty.PlaybackState(ctx)
+	if err != nil {
+		rErr := NewError(
+			"no-active-playback",
+			"No active playback currently.",
+			http.StatusNotFound,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to retrieve playback state", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusNotFound, rErr)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+type recentQuery struct {
+	Limit  int   `form:"limit" binding:"omitempty,min=1,max=50"`
+	Before int64 `form:"before" binding:"omitempty,min=0"`
+	After  int64 `form:"after" binding:"omitempty,min=0"`
+	Colors bool  `form:"colors"`
+}
+
+// handleRecentlyPlayed godoc
+// @Summary Recently Played Tracks
+// @Description returns the recently played tracks using cursor-based pagination
+// @Tags spoty
+// @Produce json
+// @Param limit query int false "maximum number of items to return (1-50)" default(50)
+// @Param before query int false "unix timestamp in milliseconds; returns items played before it"
+// @Param after query int false "unix timestamp in milliseconds; returns items played after it"
+// @Param colors query bool false "whether to include the dominant colors of each item's album images"
+// @Success 200 {object} spoty.RecentlyPlayed "returns recently played tracks"
+// @Failure 400 {object} http.Error "invalid query parameters"
+// @Failure 401 {object} http.Error "not authenticated"
+// @Failure 500 {object} http.Error "recently played tracks could not be retrieved"
+// @Router /api/recent [get]
+func (s *Server) handleRecentlyPlayed(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query recentQuery
+	if err := c.ShouldBindQuery(&query); err != nil || (query.Before != 0 && query.After != 0) {
+		detail := "Only one of before or after may be set."
+		if err != nil {
+			detail = err.Error()
+		}
+
+		rErr := NewError(
+			"invalid-query-parameters",
+			"Invalid query parameters.",
+			http.StatusBadRequest,
+			detail,
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to parse query parameters", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusBadRequest, rErr)
+
+		return
+	}
+
+	recent, err := s.spoty.RecentlyPlayed(ctx, spoty.RecentOptions{
+		Limit:  query.Limit,
+		Before: query.Before,
+		After:  query.After,
+		Colors: query.Colors,
+	})
+	if err != nil {
+		rErr := NewError(
+			"failed-retrieve-recently-played",
+			"Could not retrieve recently played tracks.",
+			http.StatusInternalServerError,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to retrieve recently played tracks", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusInternalServerError, rErr)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, recent)
+}
+
+type historyQuery struct {
+	From   time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit  int       `form:"limit" binding:"omitempty,min=1,max=500"`
+	Cursor string    `form:"cursor"`
+}
+
+// handleHistory godoc
+// @Summary Listening History
+// @Description returns the locally recorded listening history, most recent first
+// @Tags spoty
+// @Produce json
+// @Param from query string false "RFC3339 time; returns plays started at or after it"
+// @Param to query string false "RFC3339 time; returns plays started before it"
+// @Param limit query int false "maximum number of plays to return (1-500)" default(50)
+// @Param cursor query string false "cursor returned as next by the previous page"
+// @Success 200 {object} history.Page "returns a page of plays"
+// @Failure 400 {object} http.Error "invalid query parameters"
+// @Failure 401 {object} http.Error "not authenticated"
+// @Failure 500 {object} http.Error "history could not be retrieved"
+// @Router /api/history [get]
+func (s *Server) handleHistory(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query historyQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		rErr := NewError(
+			"invalid-query-parameters",
+			"Invalid query parameters.",
+			http.StatusBadRequest,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to parse query parameters", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusBadRequest, rErr)
+
+		return
+	}
+
+	page, err := s.history.List(ctx, history.Query{
+		From:   query.From,
+		To:     query.To,
+		Limit:  query.Limit,
+		Cursor: query.Cursor,
+	})
+	if err != nil {
+		rErr := NewError(
+			"failed-retrieve-history",
+			"Could not retrieve listening history.",
+			http.StatusInternalServerError,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to retrieve listening history", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusInternalServerError, rErr)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+type exportQuery struct {
+	From   time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Format string    `form:"format,default=ndjson" binding:"oneof=csv ndjson json listenbrainz"`
+}
+
+// handleHistoryExport godoc
+// @Summary Export Listening History
+// @Description streams the locally recorded listening history, oldest first, as CSV, NDJSON, JSON or a ListenBrainz import
+// @Tags spoty
+// @Produce json,text/csv,application/x-ndjson
+// @Param from query string false "RFC3339 time; exports plays started at or after it"
+// @Param to query string false "RFC3339 time; exports plays started before it"
+// @Param format query string false "export format" Enums(csv, ndjson, json, listenbrainz) default(ndjson)
+// @Success 200 {file} file "returns the exported plays"
+// @Failure 400 {object} http.Error "invalid query parameters"
+// @Failure 401 {object} http.Error "not authenticated"
+// @Router /api/history/export [get]
+func (s *Server) handleHistoryExport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query exportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		rErr := NewError(
+			"invalid-query-parameters",
+			"Invalid query parameters.",
+			http.StatusBadRequest,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to parse query parameters", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusBadRequest, rErr)
+
+		return
+	}
+
+	format := history.Format(query.Format)
+
+	// A large history takes longer to stream than the write timeout of the server.
+	if err := setWriteDeadline(ctx, time.Now().Add(_exportWriteTimeout)); err != nil {
+		s.logger.WarnwContext(ctx, "failed to extend the write deadline of the export", "error", err.Error())
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="spoty-history.`+format.Extension()+`"`)
+	c.Status(http.StatusOK)
+
+	// The response is streamed: once the first bytes are sent, errors can only be logged.
+	n, err := s.history.Export(ctx, c.Writer, history.ExportQuery{
+		From:   query.From,
+		To:     query.To,
+		Format: format,
+	})
+	if err != nil {
+		s.logger.ErrorwContext(ctx, "failed to export listening history", "error", err.Error(), "exported", n)
+		c.Abort()
+
+		return
+	}
+
+	s.logger.Ctx(ctx).Debugw("exported listening history", "format", format, "exported", n)
+}
+
+type sessionsQuery struct {
+	From   time.Time     `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     time.Time     `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Gap    time.Duration `form:"gap" binding:"omitempty,min=1m,max=24h"`
+	Limit  int           `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string        `form:"cursor"`
+}
+
+// handleSessions godoc
+// @Summary Listening Sessions
+// @Description returns the listening sessions (continuous listening separated by breaks), most recent first
+// @Tags spoty
+// @Produce json
+// @Param from query string false "RFC3339 time; only considers plays started at or after it"
+// @Param to query string false "RFC3339 time; only considers plays started before it"
+// @Param gap query string false "break after which a new session starts (e.g. 30m)" default(20m)
+// @Param limit query int false "maximum number of sessions to return (1-100)" default(20)
+// @Param cursor query string false "cursor returned as next by the previous page"
+// @Success 200 {object} history.SessionPage "returns a page of sessions"
+// @Failure 400 {object} http.Error "invalid query parameters"
+// @Failure 401 {object} http.Error "not authenticated"
+// @Failure 500 {object} http.Error "sessions could not be retrieved"
+// @Router /api/sessions [get]
+func (s *Server) handleSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query sessionsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		rErr := NewError(
+			"invalid-query-parameters",
+			"Invalid query parameters.",
+			http.StatusBadRequest,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to parse query parameters", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusBadRequest, rErr)
+
+		return
+	}
+
+	gap := query.Gap
+	if gap == 0 {
+		gap = s.sessionGap
+	}
+
+	page, err := s.history.Sessions(ctx, history.SessionQuery{
+		From:   query.From,
+		To:     query.To,
+		Gap:    gap,
+		Limit:  query.Limit,
+		Cursor: query.Cursor,
+	})
+	if err != nil {
+		rErr := NewError(
+			"failed-retrieve-sessions",
+			"Could not retrieve listening sessions.",
+			http.StatusInternalServerError,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to retrieve listening sessions", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusInternalServerError, rErr)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+type statsQuery struct {
+	From  time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To    time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit int       `form:"limit" binding:"omitempty,min=1,max=50"`
+	TZ    string    `form:"tz"`
+}
+
+// handleStats godoc
+// @Summary Listening Statistics
+// @Description returns top tracks, artists, albums and genres, minutes listened, streaks and listening distributions over a time window
+// @Description distributions are computed from hourly UTC rollups: in time zones whose offset is not a whole number of hours (e.g. Asia/Kolkata), plays are counted in the local hour their UTC hour starts in
+// @Tags spoty
+// @Produce json
+// @Param from query string false "RFC3339 time; only considers plays started at or after it"
+// @Param to query string false "RFC3339 time; only considers plays started before it (defaults to now)"
+// @Param limit query int false "maximum number of top items to return (1-50)" default(10)
+// @Param tz query string false "IANA time zone used for distributions and streaks" default(UTC)
+// @Success 200 {object} history.Stats "returns listening statistics"
+// @Failure 400 {object} http.Error "invalid query parameters"
+// @Failure 401 {object} http.Error "not authenticated"
+// @Failure 500 {object} http.Error "statistics could not be computed"
+// @Router /api/stats [get]
+func (s *Server) handleStats(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query statsQuery
+	err := c.ShouldBindQuery(&query)
+
+	loc := time.UTC
+	if err == nil && query.TZ != "" {
+		loc, err = time.LoadLocation(query.TZ)
+	}
+
+	if err == nil && !query.From.IsZero() && !query.To.IsZero() && query.From.After(query.To) {
+		err = errors.New("from must not be after to")
+	}
+
+	if err != nil {
+		rErr := NewError(
+			"invalid-query-parameters",
+			"Invalid query parameters.",
+			http.StatusBadRequest,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to parse query parameters", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusBadRequest, rErr)
+
+		return
+	}
+
+	stats, err := s.history.Stats(ctx, history.StatsQuery{
+		From:     query.From,
+		To:       query.To,
+		Limit:    query.Limit,
+		Location: loc,
+	})
+	if err != nil {
+		rErr := NewError(
+			"failed-compute-stats",
+			"Could not compute listening statistics.",
+			http.StatusInternalServerError,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to compute listening statistics", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusInternalServerError, rErr)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// handleRecentAtomFeed godoc
+// @Summary Recent Listens Atom Feed
+// @Description returns the most recent plays as an Atom feed; supports conditional requests
+// @Tags feeds
+// @Produce application/atom+xml
+// @Param token query string true "feed token"
+// @Success 200 {string} string "returns the Atom feed"
+// @Success 304 {string} string "feed not modified"
+// @Failure 401 {object} http.Error "invalid feed token"
+// @Failure 404 {object} http.Error "feed disabled"
+// @Failure 500 {object} http.Error "history could not be retrieved"
+// @Router /feeds/recent.atom [get]
+func (s *Server) handleRecentAtomFeed(c *gin.Context) {
+	s.serveRecentFeed(c, "application/atom+xml; charset=utf-8", (*feed.Feed).WriteAtom)
+}
+
+// handleRecentRSSFeed godoc
+// @Summary Recent Listens RSS Feed
+// @Description returns the most recent plays as an RSS feed; supports conditional requests
+// @Tags feeds
+// @Produce application/rss+xml
+// @Param token query string true "feed token"
+// @Success 200 {string} string "returns the RSS feed"
+// @Success 304 {string} string "feed not modified"
+// @Failure 401 {object} http.Error "invalid feed token"
+// @Failure 404 {object} http.Error "feed disabled"
+// @Failure 500 {object} http.Error "history could not be retrieved"
+// @Router /feeds/recent.rss [get]
+func (s *Server) handleRecentRSSFeed(c *gin.Context) {
+	s.serveRecentFeed(c, "application/rss+xml; charset=utf-8", (*feed.Feed).WriteRSS)
+}
+
+// handleNowPlayingCard godoc
+// @Summary Now Playing Card
+// @Description returns an SVG card of the current playing item (title, artists, progress bar and cover) themed with the dominant color of its cover, meant to be embedded in READMEs and status pages; shows the last played track when nothing is playing; supports conditional requests
+// @Tags cards
+// @Produce image/svg+xml
+// @Param layout query string false "layout of the card: a small badge, a banner or a tile with a large cover" Enums(compact, classic, cover) default(classic)
+// @Success 200 {string} string "returns the card"
+// @Success 304 {string} string "card not modified"
+// @Failure 400 {object} http.Error "invalid query parameters"
+// @Failure 401 {object} http.Error "not authenticated"
+// @Failure 500 {object} http.Error "card could not be rendered"
+// @Router /card/now-playing.svg [get]
+func (s *Server) handleNowPlayingCard(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query struct {
+		Layout string `form:"layout" binding:"omitempty,oneof=compact classic cover"`
+	}
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		rErr := NewError(
+			"invalid-query-parameters",
+			"Invalid query parameters.",
+			http.StatusBadRequest,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to parse query parameters", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusBadRequest, rErr)
+
+		return
+	}
+
+	layout := card.LayoutClassic
+	if query.Layout != "" {
+		layout = card.Layout(query.Layout)
+	}
+
+	nowPlaying := s.nowPlayingCard(ctx, 2*layout.CoverSize(), false)
+
+	var buf bytes.Buffer
+	if err := nowPlaying.WriteSVG(&buf, layout, time.Now()); err != nil {
+		rErr := NewError(
+			"failed-render-card",
+			"Could not render card.",
+			http.StatusInternalServerError,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to render card", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusInternalServerError, rErr)
+
+		return
+	}
+
+	etag := cover.ETag(buf.Bytes())
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", cardCacheControl(nowPlaying.State))
+	c.Header("Content-Security-Policy", _cardContentSecurityPolicy)
+
+	if notModified(c.Request, etag, time.Time{}) {
+		c.Status(http.StatusNotModified)
+
+		return
+	}
+
+	c.Data(http.StatusOK, "image/svg+xml; charset=utf-8", buf.Bytes())
+}
+
+// handleNowPlayingPNGCard godoc
+// @Summary Now Playing PNG Card
+// @Description returns a PNG card of the current playing item (title, artists, progress bar and cover) on a gradient of the colors of its cover, for places which do not accept SVG images such as chat previews; shows the last played track when nothing is playing; supports conditional requests
+// @Tags cards
+// @Produce image/png
+// @Param size query string false "size of the card: small (600x200), og (1200x630), square (1080x1080) or story (1080x1920)" Enums(small, og, square, story) default(og)
+// @Success 200 {file} file "returns the card"
+// @Success 304 {string} string "card not modified"
+// @Failure 400 {object} http.Error "invalid query parameters"
+// @Failure 401 {object} http.Error "not authenticated"
+// @Failure 500 {object} http.Error "card could not be rendered"
+// @Router /card/now-playing.png [get]
+func (s *Server) handleNowPlayingPNGCard(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query struct {
+		Size string `form:"size" binding:"omitempty,oneof=small og square story"`
+	}
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		rErr := NewError(
+			"invalid-query-parameters",
+			"Invalid query parameters.",
+			http.StatusBadRequest,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to parse query parameters", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusBadRequest, rErr)
+
+		return
+	}
+
+	preset := card.PresetOpenGraph
+	if query.Size != "" {
+		preset = card.Preset(query.Size)
+	}
+
+	nowPlaying := s.nowPlayingCard(ctx, preset.CoverSize(), true)
+
+	rendered, err := s.renderPNGCard(ctx, nowPlaying, preset, time.Now())
+	if err != nil {
+		rErr := NewError(
+			"failed-render-card",
+			"Could not render card.",
+			http.StatusInternalServerError,
+			err.Error(),
+			c.Request.URL.String(),
+			nil,
+		)
+
+		s.logger.ErrorwContext(ctx, "failed to render card", "error", rErr.Error())
+		c.AbortWithStatusJSON(http.StatusInternalServerError, rErr)
+
+		return
+	}
+
+	c.Header("ETag", rendered.ETag)
+	c.Header("Cache-Control", cardCacheControl(nowPlaying.State))
+
+	if notModified(c.Request, rendered.ETag, time.Time{}) {
+		c.Status(http.StatusNotModified)
+
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", rendered.Data)
+}
+
 // handleAuthenticate godoc
 // @Summary Authentication
 // @Description redirects user to spotify for authentication
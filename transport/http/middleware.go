@@ -1,11 +1,34 @@
 package http
 
 import (
+	"context"
+	"crypto/subtle"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
+// feedTokenKey is the context key of the token of a feed request.
+type feedTokenKey struct{}
+
+// stripFeedToken moves the token of a request from its query to its context before
+// the request reaches the router, so that it is neither logged nor traced.
+func stripFeedToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if _, ok := query["token"]; ok {
+			token := query.Get("token")
+			query.Del("token")
+
+			r = r.Clone(context.WithValue(r.Context(), feedTokenKey{}, token))
+			r.URL.RawQuery = query.Encode()
+			r.RequestURI = r.URL.RequestURI()
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (s *Server) unauthenticatedOnly() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if s.spoty.IsAuth() {
@@ -51,3 +74,46 @@ func (s *Server) authenticatedOnly() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// feedToken restricts the access to a feed to the requests bearing its token, taken out of their query by stripFeedToken.
+// The feed is disabled if no token is configured.
+func (s *Server) feedToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		if token == "" {
+			rErr := NewError(
+				"feed-disabled",
+				"Feed not found.",
+				http.StatusNotFound,
+				"This feed is disabled as no token is configured for it.",
+				c.Request.URL.Path,
+				nil,
+			)
+
+			s.logger.ErrorwContext(ctx, "failed to access feed", "error", rErr.Error())
+			c.AbortWithStatusJSON(http.StatusNotFound, rErr)
+
+			return
+		}
+
+		given, _ := ctx.Value(feedTokenKey{}).(string)
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			rErr := NewError(
+				"invalid-feed-token",
+				"You do not have access.",
+				http.StatusUnauthorized,
+				"You cannot access this feed because the token is missing or invalid.",
+				c.Request.URL.Path,
+				nil,
+			)
+
+			s.logger.ErrorwContext(ctx, "failed to access feed", "error", rErr.Error())
+			c.AbortWithStatusJSON(http.StatusUnauthorized, rErr)
+
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -2,15 +2,20 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
+	"github.com/dgraph-io/ristretto"
 	ginzap "github.com/gin-contrib/zap"
 	"github.com/gin-gonic/gin"
 	"github.com/mgjules/spoty/build"
+	"github.com/mgjules/spoty/card"
 	"github.com/mgjules/spoty/config"
 	"github.com/mgjules/spoty/health"
+	"github.com/mgjules/spoty/history"
 	"github.com/mgjules/spoty/logger"
 	"github.com/mgjules/spoty/spoty"
 	"github.com/mgjules/spoty/tracer"
@@ -23,24 +28,37 @@ const (
 	_writeTimeout      = 2 * time.Second
 	_idleTimeout       = 30 * time.Second
 	_readHeaderTimeout = 2 * time.Second
+	// _uploadReadTimeout replaces the read timeout of the uploads of analyzed images.
+	_uploadReadTimeout = 30 * time.Second
+	// _exportWriteTimeout replaces the write timeout of the streamed history exports.
+	_exportWriteTimeout = 10 * time.Minute
 )
 
 // Module exported for initialising a new Server and Client.
 var Module = fx.Options(
 	fx.Provide(NewServer),
 	fx.Provide(NewClient),
+	fx.Provide(NewFetcher),
 )
 
 // Server is the main HTTP server.
 type Server struct {
-	router *gin.Engine
-	http   *http.Server
-	logger *logger.Logger
-	tracer *tracer.Tracer
-	spoty  *spoty.Spoty
-	health *health.Checks
-	build  *build.Info
-	addr   string
+	router  *gin.Engine
+	http    *http.Server
+	logger  *logger.Logger
+	tracer  *tracer.Tracer
+	spoty   *spoty.Spoty
+	history *history.History
+	health  *health.Checks
+	build   *build.Info
+	fonts   *card.Fonts
+	// pngCards caches the rendered PNG cards, apart from the shared cache as they are large.
+	pngCards *ristretto.Cache
+	addr     string
+
+	sessionGap    time.Duration
+	atomFeedToken string
+	rssFeedToken  string
 }
 
 // NewServer creates a new Server.
@@ -49,25 +67,44 @@ func NewServer(
 	logger *logger.Logger,
 	tracer *tracer.Tracer,
 	spoty *spoty.Spoty,
+	history *history.History,
 	health *health.Checks,
 	build *build.Info,
-) *Server {
+	fonts *card.Fonts,
+) (*Server, error) {
 	if cfg.Prod {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	pngCards, err := ristretto.NewCache(&ristretto.Config{
+		NumCounters: _pngCardCacheKeys,
+		MaxCost:     _pngCardCacheBytes,
+		BufferItems: 64,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create card cache: %w", err)
+	}
+
 	w := logger.Writer()
 	gin.DefaultWriter = w
 	gin.DefaultErrorWriter = w
 
 	s := Server{
-		router: gin.Default(),
-		addr:   fmt.Sprintf("%s:%d", cfg.HttpServerHost, cfg.HttpServerPort),
-		logger: logger,
-		tracer: tracer,
-		spoty:  spoty,
-		health: health,
-		build:  build,
+		router:  gin.Default(),
+		addr:    fmt.Sprintf("%s:%d", cfg.HttpServerHost, cfg.HttpServerPort),
+		logger:  logger,
+		tracer:  tracer,
+		spoty:   spoty,
+		history: history,
+		health:  health,
+		build:   build,
+		fonts:   fonts,
+
+		pngCards: pngCards,
+
+		sessionGap:    cfg.SessionGap,
+		atomFeedToken: cfg.FeedAtomToken,
+		rssFeedToken:  cfg.FeedRSSToken,
 	}
 
 	desugared := logger.Desugar()
@@ -76,16 +113,46 @@ func NewServer(
 
 	s.http = &http.Server{
 		Addr:              s.addr,
-		Handler:           s.router,
+		Handler:           stripFeedToken(s.router),
 		ReadTimeout:       _readTimeout,
 		WriteTimeout:      _writeTimeout,
 		IdleTimeout:       _idleTimeout,
 		ReadHeaderTimeout: _readHeaderTimeout,
+		ConnContext:       withConn,
 	}
 
 	s.registerRoutes()
 
-	return &s
+	return &s, nil
+}
+
+// connKey is the context key of the connection of a request.
+type connKey struct{}
+
+// withConn stores the connection of the requests in their context,
+// so that the handlers of slow routes can replace the timeouts of the server.
+func withConn(ctx context.Context, conn net.Conn) context.Context {
+	return context.WithValue(ctx, connKey{}, conn)
+}
+
+// setReadDeadline replaces the read deadline of the connection of a request.
+func setReadDeadline(ctx context.Context, deadline time.Time) error {
+	conn, ok := ctx.Value(connKey{}).(net.Conn)
+	if !ok {
+		return errors.New("no connection in context")
+	}
+
+	return conn.SetReadDeadline(deadline)
+}
+
+// setWriteDeadline replaces the write deadline of the connection of a request.
+func setWriteDeadline(ctx context.Context, deadline time.Time) error {
+	conn, ok := ctx.Value(connKey{}).(net.Conn)
+	if !ok {
+		return errors.New("no connection in context")
+	}
+
+	return conn.SetWriteDeadline(deadline)
 }
 
 func (s *Server) registerRoutes() {
@@ -115,8 +182,39 @@ func (s *Server) registerRoutes() {
 		{
 			authenticated.GET("/current", s.handleCurrentTrack)
 			authenticated.GET("/current/images", s.handleCurrentTrackImages)
+			authenticated.GET("/current/theme.css", s.handleCurrentTheme)
+			authenticated.GET("/current/scheme", s.handleCurrentScheme)
+			authenticated.GET("/current/cover", s.handleCurrentCover)
+			authenticated.GET("/current/bitmap", s.handleCurrentBitmap)
+			authenticated.GET("/tracks/:id/images", s.handleTrackImages)
+			authenticated.GET("/albums/:id/images", s.handleAlbumImages)
+			authenticated.POST("/colors", s.handleBatchColors)
+			authenticated.POST("/analyze", s.handleAnalyze)
+			authenticated.GET("/player", s.handleCurrentPlayer)
+			authenticated.GET("/recent", s.handleRecentlyPlayed)
+			authenticated.GET("/history", s.handleHistory)
+			authenticated.GET("/history/export", s.handleHistoryExport)
+			authenticated.GET("/stats", s.handleStats)
+			authenticated.GET("/sessions", s.handleSessions)
 		}
 	}
+
+	// Feeds are protected by their own token so that they can be shared with feed readers.
+	feeds := s.router.Group("/feeds")
+	feeds.Use(otelgin.Middleware("main"))
+	{
+		feeds.GET("/recent.atom", s.feedToken(s.atomFeedToken), s.handleRecentAtomFeed)
+		feeds.GET("/recent.rss", s.feedToken(s.rssFeedToken), s.handleRecentRSSFeed)
+	}
+
+	// Cards are images meant to be embedded in pages, hence outside of the API.
+	cards := s.router.Group("/card")
+	cards.Use(otelgin.Middleware("main"))
+	cards.Use(s.authenticatedOnly())
+	{
+		cards.GET("/now-playing.svg", s.handleNowPlayingCard)
+		cards.GET("/now-playing.png", s.handleNowPlayingPNGCard)
+	}
 }
 
 // Start starts the server.
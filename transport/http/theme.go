@@ -0,0 +1,101 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/mgjules/spoty/colors"
+	"github.com/mgjules/spoty/palette"
+	"github.com/mgjules/spoty/spoty"
+)
+
+const (
+	// _themePrefix is the prefix of the CSS custom properties of a theme.
+	_themePrefix = "--spoty-"
+	// _themeCacheControl matches the lifetime of the cached current item.
+	_themeCacheControl = "private, max-age=5, must-revalidate"
+)
+
+// themeImage returns the first image whose colors could be processed.
+// Images are sorted by size, largest first.
+func themeImage(images []spoty.Image) *spoty.Image {
+	for i := range images {
+		if images[i].Error == "" && images[i].Spaces != nil {
+			return &images[i]
+		}
+	}
+
+	return nil
+}
+
+// newThemeCSS returns a stylesheet declaring the colors of an image as CSS custom properties.
+func newThemeCSS(item *spoty.Item, img *spoty.Image) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "/* %s */\n", strings.ReplaceAll(item.Name(), "*/", "* /"))
+	buf.WriteString(":root {\n")
+
+	if img.Contrast != nil {
+		writeThemeProperty(&buf, "scheme", img.Contrast.Classification)
+	}
+
+	writeThemeColor(&buf, "primary", img.Hex, img.Spaces, img.Contrast)
+
+	if p := img.Palette; p != nil {
+		swatches := []struct {
+			name  string
+			color *palette.Color
+		}{
+			{"vibrant", p.Swatches.Vibrant},
+			{"dark-vibrant", p.Swatches.DarkVibrant},
+			{"light-vibrant", p.Swatches.LightVibrant},
+			{"muted", p.Swatches.Muted},
+			{"dark-muted", p.Swatches.DarkMuted},
+			{"light-muted", p.Swatches.LightMuted},
+		}
+
+		for _, swatch := range swatches {
+			if swatch.color != nil {
+				writeThemeColor(&buf, swatch.name, swatch.color.Hex, swatch.color.Spaces, swatch.color.Contrast)
+			}
+		}
+
+		for i := range p.Colors {
+			writeThemeProperty(&buf, fmt.Sprintf("palette-%d", i+1), p.Colors[i].Hex)
+		}
+	}
+
+	buf.WriteString("}\n")
+
+	return buf.Bytes()
+}
+
+// writeThemeColor writes the properties of a color: its hex value, its components in
+// other color spaces and the colors of text drawn on top of it.
+func writeThemeColor(buf *bytes.Buffer, name, hex string, spaces *colors.Spaces, contrast *palette.Contrast) {
+	writeThemeProperty(buf, name, hex)
+
+	if spaces != nil {
+		writeThemeProperty(buf, name+"-hsl", spaces.HSL.CSS())
+		writeThemeProperty(buf, name+"-oklch", spaces.OKLCH.CSS())
+	}
+
+	if contrast != nil {
+		writeThemeProperty(buf, "on-"+name, contrast.Text.Hex)
+		writeThemeProperty(buf, "on-"+name+"-secondary", contrast.SecondaryText.Hex)
+	}
+}
+
+func writeThemeProperty(buf *bytes.Buffer, name, value string) {
+	buf.WriteString("  " + _themePrefix + name + ": " + value + ";\n")
+}
+
+// themeETag returns a strong ETag identifying a stylesheet.
+func themeETag(css []byte) string {
+	h := fnv.New64a()
+	h.Write(css) //nolint: errcheck
+
+	return fmt.Sprintf(`"%016x"`, h.Sum64())
+}
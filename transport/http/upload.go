@@ -0,0 +1,50 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// _uploadField is the multipart form field holding an uploaded image.
+	_uploadField = "image"
+	// _multipartOverhead is the room left for the boundaries and headers of a multipart upload.
+	_multipartOverhead = 64 << 10
+)
+
+// uploadedImage returns the reader of an uploaded image: the "image" part of a multipart
+// form or the raw request body. The request body is capped slightly above the size limit
+// of analyzed images; the limit itself is enforced while reading the image.
+func (s *Server) uploadedImage(c *gin.Context) (io.Reader, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, s.spoty.AnalyzeLimits().MaxBytes+_multipartOverhead)
+
+	mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		return c.Request.Body, nil
+	}
+
+	mr, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("multipart reader: %w", err)
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("missing %q field", _uploadField)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("next part: %w", err)
+		}
+
+		if part.FormName() == _uploadField {
+			return part, nil
+		}
+	}
+}